@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Command is the definition of a command of an application that has
+// commands, e.g. the serve in app serve. Each command has its own flags.
+type Command struct {
+	Name  string `json:"name"`  // the name of the command, as used on the command line.
+	Usage string `json:"usage"` // a short description of the command.
+	Flags []Flag `json:"flags"` // the command's flags.
+}
+
+// ident returns the identifier that the names of the command's generated
+// funcs and vars start with: the command name in camel case with the first
+// letter lower-cased, e.g. list-all becomes listAll.
+func (c Command) ident() string {
+	s := Flag{Name: c.Name}.Field()
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
+
+// File returns the name of the file that the command's usage and main funcs
+// are in.
+func (c Command) File() string {
+	return c.Name + "_cmd.go"
+}
+
+// MainFunc returns the name of the command's main func, e.g. serveMain.
+func (c Command) MainFunc() string {
+	return c.ident() + "Main"
+}
+
+// UsageFunc returns the name of the command's usage func, e.g. serveUsage.
+func (c Command) UsageFunc() string {
+	return c.ident() + "Usage"
+}
+
+// FlagsVar returns the name of the var of the command's flagSet, e.g.
+// serveFlags.
+func (c Command) FlagsVar() string {
+	return c.ident() + "Flags"
+}
+
+// CfgVar returns the name of the var of the command's configuration, e.g.
+// serveCfg.
+func (c Command) CfgVar() string {
+	return c.ident() + "Cfg"
+}
+
+// ConfigType returns the name of the type of the command's configuration,
+// e.g. serveConfig.
+func (c Command) ConfigType() string {
+	return c.ident() + "Config"
+}
+
+// validate checks that the command's name is usable and that its flags are
+// valid.
+func (c Command) validate() error {
+	if c.Name == "help" {
+		return fmt.Errorf("help is reserved for the help command")
+	}
+	if !isIdent(c.ident()) {
+		return fmt.Errorf("%q is not usable as an identifier", c.ident())
+	}
+	return validateFlags(c.Flags, nil, nil)
+}
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestCommandNames(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		mainFunc  string
+		usageFunc string
+		flagsVar  string
+		cfgVar    string
+		config    string
+	}{
+		{"serve", "serve_cmd.go", "serveMain", "serveUsage", "serveFlags", "serveCfg", "serveConfig"},
+		{"list-all", "list-all_cmd.go", "listAllMain", "listAllUsage", "listAllFlags", "listAllCfg", "listAllConfig"},
+		{"Get", "Get_cmd.go", "getMain", "getUsage", "getFlags", "getCfg", "getConfig"},
+	}
+	for i, test := range tests {
+		c := Command{Name: test.name}
+		if c.File() != test.file {
+			t.Errorf("%d: file: got %q want %q", i, c.File(), test.file)
+		}
+		if c.MainFunc() != test.mainFunc {
+			t.Errorf("%d: main func: got %q want %q", i, c.MainFunc(), test.mainFunc)
+		}
+		if c.UsageFunc() != test.usageFunc {
+			t.Errorf("%d: usage func: got %q want %q", i, c.UsageFunc(), test.usageFunc)
+		}
+		if c.FlagsVar() != test.flagsVar {
+			t.Errorf("%d: flags var: got %q want %q", i, c.FlagsVar(), test.flagsVar)
+		}
+		if c.CfgVar() != test.cfgVar {
+			t.Errorf("%d: cfg var: got %q want %q", i, c.CfgVar(), test.cfgVar)
+		}
+		if c.ConfigType() != test.config {
+			t.Errorf("%d: config type: got %q want %q", i, c.ConfigType(), test.config)
+		}
+	}
+}
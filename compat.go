@@ -0,0 +1,308 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// The kinds of license in the compatibility matrix.
+const (
+	kindPermissive       = "permissive"        // anything may link to it
+	kindWeakCopyleft     = "weak copyleft"     // its own files stay under it, but anything may link to it
+	kindStrongCopyleft   = "strong copyleft"   // anything that links to it must be distributed under it, or a compatible license
+	kindLinkingException = "linking exception" // a strong copyleft license with an exception that lets anything link to it
+)
+
+// licenseKinds is the kind of each license in the compatibility matrix. The
+// GNU licenses' -only and -or-later versions are the kind of their base
+// license.
+var licenseKinds = map[License]string{
+	BSD0Clause:     kindPermissive,
+	Apache20:       kindPermissive,
+	"Artistic-2.0": kindPermissive,
+	BSD2Clause:     kindPermissive,
+	BSD3Clause:     kindPermissive,
+	"BSL-1.0":      kindPermissive,
+	CC010:          kindPermissive,
+	ISC:            kindPermissive,
+	MIT:            kindPermissive,
+	Unlicense:      kindPermissive,
+	"Zlib":         kindPermissive,
+	"CDDL-1.0":     kindWeakCopyleft,
+	"EPL-1.0":      kindWeakCopyleft,
+	EPL20:          kindWeakCopyleft,
+	LGPL20:         kindWeakCopyleft,
+	LGPL21:         kindWeakCopyleft,
+	LGPL30:         kindWeakCopyleft,
+	"MPL-1.1":      kindWeakCopyleft,
+	MPL20:          kindWeakCopyleft,
+	AGPL30:         kindStrongCopyleft,
+	AGPL30Only:     kindStrongCopyleft,
+	AGPL30OrLater:  kindStrongCopyleft,
+	"EUPL-1.2":     kindStrongCopyleft,
+	GPL20:          kindStrongCopyleft,
+	GPL30:          kindStrongCopyleft,
+}
+
+// linkingExceptions are the exceptions that let anything link to a strong
+// copyleft license's code without being distributed under that license.
+var linkingExceptions = map[string]bool{
+	"Classpath-exception-2.0": true,
+	"GCC-exception-3.1":       true,
+	"LLVM-exception":          true,
+	"Linux-syscall-note":      true,
+}
+
+// onlyVersions are the -only versions of the deprecated IDs: a license's text
+// doesn't say whether it's the -only or the -or-later license, so it's checked
+// as the -only license.
+var onlyVersions = map[License]License{
+	AGPL30: AGPL30Only,
+	GPL20:  GPL20Only,
+	GPL30:  GPL30Only,
+	LGPL20: LGPL20Only,
+	LGPL21: LGPL21Only,
+	LGPL30: LGPL30Only,
+}
+
+// copyleftLicenses are the licenses that code under a strong copyleft license
+// can be distributed under when it's combined with other code.
+var copyleftLicenses = map[License][]License{
+	AGPL30Only:    {AGPL30Only, AGPL30OrLater, GPL30Only, GPL30OrLater},
+	AGPL30OrLater: {AGPL30Only, AGPL30OrLater, GPL30Only, GPL30OrLater},
+	"EUPL-1.2":    {"EUPL-1.2", AGPL30Only, AGPL30OrLater, GPL20Only, GPL20OrLater, GPL30Only, GPL30OrLater, LGPL21Only, LGPL21OrLater, LGPL30Only, LGPL30OrLater, MPL20, "EPL-1.0", EPL20},
+	GPL20Only:     {GPL20Only, GPL20OrLater},
+	GPL20OrLater:  {GPL20Only, GPL20OrLater, GPL30Only, GPL30OrLater, AGPL30Only, AGPL30OrLater},
+	GPL30Only:     {GPL30Only, GPL30OrLater, AGPL30Only, AGPL30OrLater},
+	GPL30OrLater:  {GPL30Only, GPL30OrLater, AGPL30Only, AGPL30OrLater},
+}
+
+// gplLicenses are the GPL and AGPL versions.
+var gplLicenses = []License{GPL20Only, GPL20OrLater, GPL30Only, GPL30OrLater, AGPL30Only, AGPL30OrLater}
+
+// incompatibility is why code under a permissive or weak copyleft license
+// can't be combined with code under any of the licenses.
+type incompatibility struct {
+	licenses []License
+	reason   string
+}
+
+// incompatibilities are the permissive and weak copyleft licenses that can't
+// be combined with code under some other licenses.
+var incompatibilities = map[License]incompatibility{
+	Apache20:      {[]License{GPL20Only}, "its patent termination and indemnification terms are additional restrictions that the GPL-2.0 doesn't allow"},
+	"CDDL-1.0":    {gplLicenses, "its copyleft terms conflict with the GPL's"},
+	"EPL-1.0":     {gplLicenses, "its copyleft terms conflict with the GPL's"},
+	LGPL30Only:    {[]License{GPL20Only}, "it's the GPL-3.0 with additional permissions, which the GPL-2.0 isn't compatible with"},
+	LGPL30OrLater: {[]License{GPL20Only}, "it's the GPL-3.0 with additional permissions, which the GPL-2.0 isn't compatible with"},
+	"MPL-1.1":     {gplLicenses, "its copyleft terms conflict with the GPL's"},
+}
+
+// kind returns the kind of license l is in the compatibility matrix; an empty
+// string if it isn't in the matrix.
+func (l License) kind() string {
+	if k, ok := licenseKinds[l]; ok {
+		return k
+	}
+	return licenseKinds[l.base()]
+}
+
+// onlyVersion returns the -only version of l, if l is a deprecated ID that
+// has one; otherwise l is returned.
+func (l License) onlyVersion() License {
+	if o, ok := onlyVersions[l]; ok {
+		return o
+	}
+	return l
+}
+
+// kind returns the kind of license the expression's license is; see
+// License.kind. A strong copyleft license with a linking exception is a
+// linking exception. An AND or OR of licenses doesn't have a kind.
+func (e *LicenseExpr) kind() string {
+	if e.Op != "" {
+		return ""
+	}
+	k := e.License.kind()
+	if k == kindStrongCopyleft && linkingExceptions[e.Exception] {
+		return kindLinkingException
+	}
+	return k
+}
+
+// conflict returns why code under the dependency's license, dep, can't be
+// combined with an app under the license, app; an empty string means that
+// they are compatible. If either is an OR of licenses, any of its licenses
+// will do; if either is an AND, all of them must be compatible. Both licenses
+// must be in the compatibility matrix; see License.kind.
+func conflict(app, dep *LicenseExpr) string {
+	for _, e := range []*LicenseExpr{dep, app} {
+		if e.Op == "" {
+			continue
+		}
+		var reasons []string
+		for _, a := range e.Args {
+			var s string
+			if e == dep {
+				s = conflict(app, a)
+			} else {
+				s = conflict(a, dep)
+			}
+			if s == "" && e.Op == opOr {
+				return ""
+			}
+			if s != "" && e.Op == opAnd {
+				return s
+			}
+			reasons = append(reasons, s)
+		}
+		if e.Op == opAnd {
+			return ""
+		}
+		return strings.Join(reasons, "; ")
+	}
+
+	a, d := app.License.onlyVersion(), dep.License.onlyVersion()
+	if a == d {
+		return ""
+	}
+	var reason string
+	switch dep.kind() {
+	case kindPermissive, kindWeakCopyleft:
+		x := incompatibilities[d]
+		for _, l := range x.licenses {
+			if l == a {
+				reason = fmt.Sprintf("%s can't be combined with %s: %s", d, a, x.reason)
+			}
+		}
+	case kindStrongCopyleft:
+		compatible := copyleftLicenses[d]
+		for _, l := range compatible {
+			if l == a {
+				return ""
+			}
+		}
+		var ids []string
+		for _, l := range compatible {
+			ids = append(ids, l.ID())
+		}
+		reason = fmt.Sprintf("%s is a strong copyleft license: the app, which links to it, must be distributed under %s, not %s", d, listJoin(ids, "or"), a)
+	}
+	if reason != "" && d != dep.License {
+		reason += fmt.Sprintf("; the %s license doesn't say whether it's %s or %s-or-later, so it's checked as %[2]s", dep.License, d, dep.License)
+	}
+	return reason
+}
+
+// The results of checking a dependency's license.
+const (
+	checkOK       = "ok"
+	checkConflict = "conflict"
+	checkUnknown  = "unknown" // the license couldn't be identified or isn't in the compatibility matrix
+)
+
+// licenseCheck is the result of checking one of a dependency's licenses.
+type licenseCheck struct {
+	Path    string // the dependency's module path and version
+	License string // the license file and its license
+	Result  string
+	Reason  string
+}
+
+// checkDependencies checks the licenses of the dependencies in the report
+// against the app's license. Each license file of a dependency is checked; a
+// dependency without any is unknown.
+func (a *App) checkDependencies(r depsReport) ([]licenseCheck, error) {
+	app := a.Expression
+	if app == nil {
+		if a.License == None {
+			return nil, errors.New("the app's license isn't set; use -license")
+		}
+		app = &LicenseExpr{License: a.License}
+	}
+	var unknown []License
+	app.walk(func(e *LicenseExpr) {
+		if e.Op == "" && e.License.kind() == "" {
+			unknown = append(unknown, e.License)
+		}
+	})
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("%s isn't in the compatibility matrix", unknown[0])
+	}
+
+	var checks []licenseCheck
+	for _, d := range r.Dependencies {
+		path := d.Path + " " + d.Version
+		if d.Note != "" {
+			checks = append(checks, licenseCheck{path, "", checkUnknown, d.Note})
+			continue
+		}
+		for _, l := range d.Licenses {
+			c := licenseCheck{path, l.File + ": " + l.License, checkOK, ""}
+			dep, err := ParseLicenseExpr(l.License)
+			if l.License == noAssertion || err != nil {
+				c.Result, c.Reason = checkUnknown, "the license wasn't identified"
+				checks = append(checks, c)
+				continue
+			}
+			known := true
+			dep.walk(func(e *LicenseExpr) {
+				known = known && (e.Op != "" || e.License.kind() != "")
+			})
+			if !known {
+				c.Result, c.Reason = checkUnknown, "the license isn't in the compatibility matrix"
+			} else if s := conflict(app, dep); s != "" {
+				c.Result, c.Reason = checkConflict, s
+			}
+			checks = append(checks, c)
+		}
+	}
+	return checks, nil
+}
+
+// checkMain runs the licenses check command: quine [flags] licenses check
+// [dir]. The licenses of the dependencies of the module in dir, or, if it
+// isn't specified, the module that the app's path is in, are checked against
+// the app's license, -license. The conflicts and the licenses that couldn't be
+// checked are listed; if there are any conflicts, 1 is returned.
+func checkMain(args []string) int {
+	if len(args) > 1 || len(args) == 1 && strings.HasPrefix(args[0], "-") {
+		log.Printf("licenses check: usage: %s [flags] licenses check [dir]; the flags go before the command", exe)
+		return 2
+	}
+	parseFlags()
+	root := app.root()
+	if len(args) > 0 {
+		root = args[0]
+	}
+
+	r, err := dependencyLicenses(root)
+	if err != nil {
+		log.Printf("licenses check: error: %s", err)
+		return 1
+	}
+	checks, err := app.checkDependencies(r)
+	if err != nil {
+		log.Printf("licenses check: error: %s", err)
+		return 1
+	}
+	counts := map[string]int{}
+	for _, c := range checks {
+		counts[c.Result]++
+		if c.Result == checkOK {
+			continue
+		}
+		if c.License != "" {
+			fmt.Printf("%-9s %s: %s: %s\n", c.Result, c.Path, c.License, c.Reason)
+			continue
+		}
+		fmt.Printf("%-9s %s: %s\n", c.Result, c.Path, c.Reason)
+	}
+	fmt.Printf("%d licenses of %d dependencies: %d ok, %d in conflict, %d unknown\n", len(checks), len(r.Dependencies), counts[checkOK], counts[checkConflict], counts[checkUnknown])
+	if counts[checkConflict] > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConflict(t *testing.T) {
+	tests := []struct {
+		app      string
+		dep      string
+		expected string // a substring of the conflict; empty if there isn't one
+	}{
+		{"MIT", "BSD-3-Clause", ""},
+		{"MIT", "Apache-2.0", ""},
+		{"MIT", "MPL-2.0", ""},
+		{"MIT", "LGPL-2.1-only", ""},
+		{"MIT", "GPL-3.0-only", "GPL-3.0-only is a strong copyleft license: the app, which links to it, must be distributed under GPL-3.0-only, GPL-3.0-or-later, AGPL-3.0-only, or AGPL-3.0-or-later, not MIT"},
+		{"MIT", "GPL-2.0 WITH Classpath-exception-2.0", ""},
+		{"MIT", "GPL-2.0 WITH Autoconf-exception-3.0", "GPL-2.0-only is a strong copyleft license"},
+		{"Apache-2.0", "AGPL-3.0-or-later", "AGPL-3.0-or-later is a strong copyleft license"},
+		{"GPL-3.0-only", "GPL-3.0", ""},
+		{"GPL-3.0-or-later", "GPL-2.0-or-later", ""},
+		{"GPL-3.0-only", "GPL-2.0", "GPL-2.0-only is a strong copyleft license: the app, which links to it, must be distributed under GPL-2.0-only or GPL-2.0-or-later, not GPL-3.0-only; the GPL-2.0 license doesn't say whether it's GPL-2.0-only or GPL-2.0-or-later, so it's checked as GPL-2.0-only"},
+		{"GPL-3.0-only", "AGPL-3.0-only", ""},
+		{"AGPL-3.0-only", "GPL-3.0-or-later", ""},
+		{"GPL-2.0-only", "Apache-2.0", "Apache-2.0 can't be combined with GPL-2.0-only: its patent termination"},
+		{"GPL-2.0-or-later", "Apache-2.0", ""},
+		{"GPL-2.0-only", "LGPL-3.0", "LGPL-3.0-only can't be combined with GPL-2.0-only"},
+		{"GPL-3.0-only", "MPL-1.1", "MPL-1.1 can't be combined with GPL-3.0-only"},
+		{"MIT OR GPL-3.0-only", "GPL-3.0-only", ""},
+		{"MIT AND Apache-2.0", "GPL-3.0-only", "not MIT"},
+		{"MIT OR Apache-2.0", "GPL-3.0-only", "not MIT; GPL-3.0-only is a strong copyleft license"},
+		{"MIT", "MIT OR GPL-3.0-only", ""},
+		{"MIT", "MIT AND GPL-3.0-only", "not MIT"},
+	}
+	for _, test := range tests {
+		app, err := ParseLicenseExpr(test.app)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dep, err := ParseLicenseExpr(test.dep)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := conflict(app, dep)
+		if test.expected == "" && s != "" || !strings.Contains(s, test.expected) {
+			t.Errorf("%s with %s: got %q want %q", test.app, test.dep, s, test.expected)
+		}
+	}
+}
+
+func TestCheckDependencies(t *testing.T) {
+	r := depsReport{
+		Module: "example.com/hoopy",
+		Dependencies: []dependency{
+			{Path: "example.com/gpl", Version: "v1.0.0", Licenses: []depLicense{{File: "COPYING", License: "GPL-3.0"}}},
+			{Path: "example.com/missing", Version: "v0.1.0", Note: "not in the module cache; run go mod download"},
+			{Path: "example.com/towel", Version: "v0.2.0", Licenses: []depLicense{{File: "LICENSE-APACHE", License: "Apache-2.0"}, {File: "LICENSE-OTHER", License: noAssertion}}},
+			{Path: "example.com/zlib", Version: "v1.2.0", Licenses: []depLicense{{File: "LICENSE", License: "Zlib"}}},
+			{Path: "example.com/cddl", Version: "v1.0.0", Licenses: []depLicense{{File: "LICENSE", License: "CDDL-1.0"}}},
+		},
+	}
+	expected := []licenseCheck{
+		{"example.com/gpl v1.0.0", "COPYING: GPL-3.0", checkConflict, "GPL-3.0-only is a strong copyleft license: the app, which links to it, must be distributed under GPL-3.0-only, GPL-3.0-or-later, AGPL-3.0-only, or AGPL-3.0-or-later, not MIT; the GPL-3.0 license doesn't say whether it's GPL-3.0-only or GPL-3.0-or-later, so it's checked as GPL-3.0-only"},
+		{"example.com/missing v0.1.0", "", checkUnknown, "not in the module cache; run go mod download"},
+		{"example.com/towel v0.2.0", "LICENSE-APACHE: Apache-2.0", checkOK, ""},
+		{"example.com/towel v0.2.0", "LICENSE-OTHER: NOASSERTION", checkUnknown, "the license wasn't identified"},
+		{"example.com/zlib v1.2.0", "LICENSE: Zlib", checkOK, ""},
+		{"example.com/cddl v1.0.0", "LICENSE: CDDL-1.0", checkOK, ""},
+	}
+	lapp := app
+	lapp.License, lapp.Expression = MIT, nil
+	checks, err := lapp.checkDependencies(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(checks) != len(expected) {
+		t.Fatalf("got %d checks want %d", len(checks), len(expected))
+	}
+	for i, c := range checks {
+		if c != expected[i] {
+			t.Errorf("%d: got %+v\nwant %+v", i, c, expected[i])
+		}
+	}
+
+	lapp.License = None
+	_, err = lapp.checkDependencies(r)
+	if err == nil || err.Error() != "the app's license isn't set; use -license" {
+		t.Errorf("got %v want a license isn't set error", err)
+	}
+	lapp.License = "CDDL-1.1"
+	_, err = lapp.checkDependencies(r)
+	if err == nil || err.Error() != "CDDL-1.1 isn't in the compatibility matrix" {
+		t.Errorf("got %v want an isn't in the compatibility matrix error", err)
+	}
+}
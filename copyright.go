@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// yearsPattern matches a year, a range of years, or a list of them, e.g.
+// 2017, 2017-2019, or 2015, 2017-2019: the value of a <year> placeholder in a
+// file that quine generated.
+const yearsPattern = `(?:19|20)\d\d(?:\s*[-,]\s*(?:19|20)\d\d)*`
+
+// yearOnlyRe matches a year.
+var yearOnlyRe = regexp.MustCompile(`\b(?:19|20)\d\d\b`)
+
+// copyright is the year and owner of the copyright in one of the app's
+// existing files.
+type copyright struct {
+	Year    string
+	Owner   string
+	Holders []Holder // the other copyright holders
+	File    string   // the file that they were first found in
+}
+
+// extractPlaceholders returns the values that the placeholders in tmpl, one
+// of a license's files, were replaced with in b, a copy of tmpl with its
+// placeholders filled; it's the inverse of App.fillPlaceholders. The values
+// are keyed by the app value that they are, e.g. valueYear; if a placeholder
+// has more than one value, the first one is used. See placeholderMatches.
+func extractPlaceholders(tmpl, b []byte, ps []placeholder) map[string]string {
+	values := map[string]string{}
+	for _, m := range placeholderMatches(tmpl, b, ps) {
+		for v, val := range m {
+			if _, ok := values[v]; !ok {
+				values[v] = val
+			}
+		}
+	}
+	return values
+}
+
+// extractHolders returns the copyright holders in b, a copy of tmpl with its
+// placeholders filled: the year and owner of each of its lines that is a
+// copyright line of tmpl, one with both placeholders, in order and without
+// any duplicates; see placeholderMatches.
+func extractHolders(tmpl, b []byte, ps []placeholder) []Holder {
+	var hs []Holder
+	seen := map[Holder]bool{}
+	for _, m := range placeholderMatches(tmpl, b, ps) {
+		h := Holder{Name: m[valueOwner], Year: m[valueYear]}
+		if h.Name == "" || h.Year == "" || seen[h] {
+			continue
+		}
+		seen[h] = true
+		hs = append(hs, h)
+	}
+	return hs
+}
+
+// placeholderMatches returns the values of the placeholders of each line of b
+// that matches a line of tmpl that has placeholders; see extractPlaceholders.
+// Every line of b is matched against each of those lines; comment markers
+// and whitespace are ignored, so b can be a Go file with tmpl as a comment.
+// An unfilled placeholder isn't a value.
+func placeholderMatches(tmpl, b []byte, ps []placeholder) []map[string]string {
+	var res []*regexp.Regexp
+	var names [][]string
+	for _, line := range strings.Split(string(tmpl), "\n") {
+		re, vals := placeholderLineRe(line, ps)
+		if re != nil {
+			res = append(res, re)
+			names = append(names, vals)
+		}
+	}
+	var matches []map[string]string
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "//")
+		for i, re := range res {
+			m := re.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			values := map[string]string{}
+			for j, v := range names[i] {
+				val := strings.TrimSpace(m[j+1])
+				if _, ok := values[v]; ok || isPlaceholder(val, ps) {
+					continue
+				}
+				values[v] = val
+			}
+			matches = append(matches, values)
+			break
+		}
+	}
+	return matches
+}
+
+// placeholderLineRe returns the regexp that matches the line of a license's
+// file, line, once its placeholders are filled, along with the app value of
+// each of its submatches. Whitespace matches any whitespace and years match
+// yearsPattern. If line doesn't have any placeholders, nil is returned.
+func placeholderLineRe(line string, ps []placeholder) (*regexp.Regexp, []string) {
+	var pattern []string
+	var values []string
+	for line != "" {
+		i, p := len(line), placeholder{}
+		for _, q := range ps {
+			if j := strings.Index(line, q.Token); j >= 0 && j < i {
+				i, p = j, q
+			}
+		}
+		for _, w := range strings.Fields(line[:i]) {
+			pattern = append(pattern, regexp.QuoteMeta(w))
+		}
+		if p.Token == "" {
+			break
+		}
+		if p.Value == valueYear {
+			pattern = append(pattern, "("+yearsPattern+")")
+		} else {
+			pattern = append(pattern, "(.+?)")
+		}
+		values = append(values, p.Value)
+		line = line[i+len(p.Token):]
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return regexp.MustCompile(`^\s*` + strings.Join(pattern, `\s*`) + `\s*$`), values
+}
+
+// existingCopyright returns the copyright of the app's existing files: its
+// LICENSE, which quine wrote with the license's placeholders filled, or, if
+// the year or owner aren't in it, the header of its main.go, in either the
+// SLH or the SPDX style. If neither has the copyright, nil is returned.
+func (a *App) existingCopyright() (*copyright, error) {
+	type source struct {
+		file string
+		tmpl func() ([]byte, error) // the file's template, with the placeholders
+	}
+	sources := []source{
+		{filepath.Join(a.Path, "LICENSE"), func() ([]byte, error) {
+			if a.License == None || a.Expression != nil { // an expression's licenses are in LICENSE-<ID> files
+				return nil, nil
+			}
+			b, _, err := readLicenseFile(licenseFiles(a.License)[0])
+			if os.IsNotExist(err) { // an SPDX license that isn't in the corpus
+				return nil, nil
+			}
+			return b, err
+		}},
+		{filepath.Join(a.Path, mainFile), func() ([]byte, error) {
+			// either header style; it may have changed since main.go was written
+			b, err := a.slhText()
+			return append(b, "\nSPDX-FileCopyrightText: <year> <owner>\n"...), err
+		}},
+	}
+	ps := append(placeholders(a.License), defaultPlaceholders...)
+	var c copyright
+	for _, src := range sources {
+		b, err := ioutil.ReadFile(src.file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		tmpl, err := src.tmpl()
+		if err != nil {
+			return nil, err
+		}
+		values := extractPlaceholders(tmpl, b, ps)
+		if c.File == "" && (values[valueYear] != "" || values[valueOwner] != "") {
+			c.File = src.file
+			if hs := extractHolders(tmpl, b, ps); len(hs) > 1 {
+				c.Holders = hs[1:]
+			}
+		}
+		if c.Year == "" {
+			c.Year = values[valueYear]
+		}
+		if c.Owner == "" {
+			c.Owner = values[valueOwner]
+		}
+		if c.Year != "" && c.Owner != "" {
+			break
+		}
+	}
+	if c.Year == "" && c.Owner == "" {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+// defaultCopyright sets the app's year and owner, unless they were set by
+// flag or the project definition, to those of the copyright of its existing
+// files, so that regenerating the app doesn't change them; see
+// existingCopyright. The existing copyright's other holders are kept too,
+// unless the owner or the holders were set.
+func (a *App) defaultCopyright(set map[string]bool) error {
+	if set["year"] && set["owner"] {
+		return nil
+	}
+	c, err := a.existingCopyright()
+	if err != nil || c == nil {
+		return err
+	}
+	if !set["year"] && c.Year != "" {
+		a.Year = c.Year
+		a.existingYear = true
+	}
+	if !set["owner"] && c.Owner != "" {
+		a.Owner = c.Owner
+		a.ownerSource = ownerCopyright
+		if !set["holder"] {
+			a.Holders = c.Holders
+		}
+	}
+	return nil
+}
+
+// isPlaceholder returns whether s is one of the placeholders' tokens.
+func isPlaceholder(s string, ps []placeholder) bool {
+	for _, p := range ps {
+		if s == p.Token {
+			return true
+		}
+	}
+	return false
+}
+
+// yearRange returns the range of years from the earliest year in from or to,
+// e.g. 2017 or 2017-2019, to the last year in to. If they are the same year,
+// or to doesn't have a year, to is returned.
+func yearRange(from, to string) string {
+	years := yearOnlyRe.FindAllString(to, -1)
+	if len(years) == 0 {
+		return to
+	}
+	last := years[len(years)-1]
+	first := last
+	for _, y := range append(yearOnlyRe.FindAllString(from, -1), years...) {
+		if y < first {
+			first = y
+		}
+	}
+	if first == last {
+		return last
+	}
+	return first + "-" + last
+}
+
+// planYears returns the app's plan; see Plan. With YearRange, if the year is
+// that of an existing copyright and any of the generated files would change,
+// the year is extended to a range that ends with the current year and the
+// files are planned again; the copyright isn't changed by regenerating files
+// that are up to date.
+func (a *App) planYears() ([]fileOp, error) {
+	ops, err := a.Plan()
+	if err != nil || !a.YearRange || !a.existingYear {
+		return ops, err
+	}
+	year := yearRange(a.Year, currentYear)
+	if year == a.Year {
+		return ops, nil
+	}
+	for _, op := range ops {
+		changed, err := op.diff(ioutil.Discard)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", op.Path, err)
+		}
+		if changed {
+			a.Year = year
+			return a.Plan()
+		}
+	}
+	return ops, nil
+}
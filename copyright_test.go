@@ -0,0 +1,211 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractPlaceholders(t *testing.T) {
+	tests := []struct {
+		name     string
+		license  License
+		tmpl     string
+		b        string
+		expected map[string]string
+	}{
+		{"mit", MIT, "MIT License\nCopyright (c) <year> <copyright holders>\n\nPermission is hereby granted", "MIT License\nCopyright (c) 2017 Ford Prefect\n\nPermission is hereby granted", map[string]string{valueYear: "2017", valueOwner: "Ford Prefect"}},
+		{"range", MIT, "Copyright (c) <year> <copyright holders>", "Copyright (c) 2015, 2017-2019  Ford Prefect", map[string]string{valueYear: "2015, 2017-2019", valueOwner: "Ford Prefect"}},
+		{"comment", GPL30, "Copyright (C) <year> <name of author>\n\nThis program is free software", "// Copyright (C) 2017 Ford Prefect\n//\n// This program is free software\n\npackage main\n", map[string]string{valueYear: "2017", valueOwner: "Ford Prefect"}},
+		{"apache", Apache20, "Copyright [yyyy] [name of copyright owner]\n", "// Copyright 2018 Zaphod Beeblebrox\n", map[string]string{valueYear: "2018", valueOwner: "Zaphod Beeblebrox"}},
+		{"unfilled owner", MIT, "Copyright (c) <year> <copyright holders>", "Copyright (c) 2017 <copyright holders>", map[string]string{valueYear: "2017"}},
+		{"unfilled year", MIT, "Copyright (c) <year> <copyright holders>", "Copyright (c) <year> Ford Prefect", map[string]string{}},
+		{"not a copy", MIT, "Copyright (c) <year> <copyright holders>", "Copyright the Vogons", map[string]string{}},
+	}
+	for _, test := range tests {
+		values := extractPlaceholders([]byte(test.tmpl), []byte(test.b), placeholders(test.license))
+		if len(values) != len(test.expected) {
+			t.Errorf("%s: got %v want %v", test.name, values, test.expected)
+			continue
+		}
+		for k, v := range test.expected {
+			if values[k] != v {
+				t.Errorf("%s: %s: got %q want %q", test.name, k, values[k], v)
+			}
+		}
+	}
+}
+
+func TestExistingCopyright(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	lapp := app
+	lapp.Name = "hoopy"
+	lapp.Commands = nil
+
+	tests := []struct {
+		name    string
+		license string
+		header  string
+		file    string
+		holders []Holder
+	}{
+		{"mit", "MIT", headerSLH, "LICENSE", nil},
+		{"gpl", "GPL-3.0-or-later", headerSLH, mainFile, nil}, // the GPL's text doesn't have the copyright,
+		{"apache", "Apache-2.0", headerSLH, mainFile, nil},
+		{"spdx", "MPL-2.0", headerSPDX, mainFile, nil},
+		{"expression", "MIT OR Apache-2.0", headerSLH, mainFile, nil},
+		{"bsd", "BSD-3-Clause", headerSLH, "LICENSE", []Holder{{"Acme Corp", "2019-2020"}, {"Zaphod Beeblebrox", "2021"}}},
+		{"spdx-holders", "MPL-2.0", headerSPDX, mainFile, []Holder{{"Acme Corp", "2019-2020"}}},
+	}
+	for _, test := range tests {
+		lapp.Path = filepath.Join(dir, test.name)
+		lapp.Header = test.header
+		lapp.Owner, lapp.Year, lapp.Holders = "Ford Prefect", "2017", test.holders
+		lapp.License, lapp.Expression = None, nil
+		e, err := ParseLicenseExpr(test.license)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.IsLicense() {
+			lapp.License = e.License
+		} else {
+			lapp.Expression = e
+		}
+		ops, err := lapp.Plan()
+		if err != nil {
+			t.Fatalf("%s: plan: %s", test.name, err)
+		}
+		for _, op := range ops {
+			err = op.write()
+			if err != nil {
+				t.Fatalf("%s: %s", test.name, err)
+			}
+		}
+
+		lapp.Owner, lapp.Year, lapp.Holders = "Arthur Dent", "2026", nil
+		c, err := lapp.existingCopyright()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		expected := copyright{"2017", "Ford Prefect", test.holders, filepath.Join(lapp.Path, test.file)}
+		if c == nil || !reflect.DeepEqual(*c, expected) {
+			t.Errorf("%s: got %+v want %+v", test.name, c, expected)
+		}
+
+		// the flags take precedence
+		err = lapp.defaultCopyright(map[string]bool{"owner": true})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+		}
+		if lapp.Owner != "Arthur Dent" || lapp.Year != "2017" || !lapp.existingYear {
+			t.Errorf("%s: got %s %s %t want Arthur Dent 2017 true", test.name, lapp.Owner, lapp.Year, lapp.existingYear)
+		}
+		if lapp.Holders != nil { // the other holders are the owner's, which was set
+			t.Errorf("%s: got holders %v want none", test.name, lapp.Holders)
+		}
+		lapp.existingYear = false
+		err = lapp.defaultCopyright(map[string]bool{})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+		}
+		if lapp.Owner != "Ford Prefect" || !reflect.DeepEqual(lapp.Holders, test.holders) {
+			t.Errorf("%s: got %s %v want Ford Prefect %v", test.name, lapp.Owner, lapp.Holders, test.holders)
+		}
+		lapp.existingYear = false
+	}
+
+	lapp.Path = filepath.Join(dir, "none")
+	c, err := lapp.existingCopyright()
+	if err != nil || c != nil {
+		t.Errorf("no files: got %v, %v want nil, nil", c, err)
+	}
+}
+
+func TestYearRange(t *testing.T) {
+	tests := []struct {
+		from     string
+		to       string
+		expected string
+	}{
+		{"2017", "2026", "2017-2026"},
+		{"2017-2019", "2026", "2017-2026"},
+		{"2015, 2017-2019", "2026", "2015-2026"},
+		{"2026", "2026", "2026"},
+		{"2027", "2026", "2026"},
+		{"2017", "2019-2026", "2017-2026"},
+		{"", "2026", "2026"},
+		{"2017", "", ""},
+	}
+	for _, test := range tests {
+		if s := yearRange(test.from, test.to); s != test.expected {
+			t.Errorf("%q %q: got %q want %q", test.from, test.to, s, test.expected)
+		}
+	}
+}
+
+func TestPlanYears(t *testing.T) {
+	var err error
+	lapp := app
+	lapp.Path, err = ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(lapp.Path)
+	defer func(y string) { currentYear = y }(currentYear)
+	currentYear = "2026"
+	lapp.Name = "hoopy"
+	lapp.License, lapp.Expression = MIT, nil
+	lapp.Header = headerSLH
+	lapp.Owner, lapp.Year = "Ford Prefect", "2017"
+	lapp.Commands = nil
+	ops, err := lapp.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, op := range ops {
+		err = op.write()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lapp.YearRange, lapp.existingYear = true, true
+	_, err = lapp.planYears()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if lapp.Year != "2017" {
+		t.Errorf("up to date: got %s want 2017", lapp.Year)
+	}
+
+	lapp.Commands = []Command{{Name: "serve"}} // main.go changes
+	ops, err = lapp.planYears()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if lapp.Year != "2017-2026" {
+		t.Errorf("changed: got %s want 2017-2026", lapp.Year)
+	}
+	for _, op := range ops {
+		if filepath.Base(op.Path) == "LICENSE" && !strings.HasPrefix(string(op.Data), "MIT License\nCopyright (c) 2017-2026 Ford Prefect\n") {
+			t.Errorf("LICENSE: got %q", op.Data)
+		}
+	}
+
+	lapp.Year, lapp.existingYear = "2017", false // not an existing copyright's year
+	_, err = lapp.planYears()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if lapp.Year != "2017" {
+		t.Errorf("not existing: got %s want 2017", lapp.Year)
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// builtIn is the source of a license file that is built into quine.
+const builtIn = "built-in"
+
+// The license corpus: the full text of each license and, if the license has
+// them, its SLH, .slh, and CLI notice, .cli, files.
+//
+//go:embed license
+var licenseFS embed.FS
+
+// licenseOverrideDir returns the directory of any license files that replace
+// the built-in ones: the -licensedir flag's value, which is relative to the
+// QUINEPATH, or the WD if the QUINEPATH isn't set, or, if the flag isn't set,
+// the license directory in the QUINEPATH. If neither is set, only the built-in
+// license files are used.
+func licenseOverrideDir() string {
+	if licenseDir != "" {
+		if filepath.IsAbs(licenseDir) {
+			return licenseDir
+		}
+		return filepath.Join(quinePath, licenseDir)
+	}
+	if quinePath != "" {
+		return filepath.Join(quinePath, "license")
+	}
+	return ""
+}
+
+// readLicenseFile returns the contents of the license file, name, e.g. mit or
+// gpl-3.0.slh, along with its source: either the path of the file in the
+// license override directory or built-in. If the file doesn't exist the
+// error satisfies os.IsNotExist.
+func readLicenseFile(name string) ([]byte, string, error) {
+	if dir := licenseOverrideDir(); dir != "" {
+		file := filepath.Join(dir, name)
+		b, err := ioutil.ReadFile(file)
+		if err == nil {
+			return b, file, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, "", err
+		}
+	}
+	b, err := fs.ReadFile(licenseFS, path.Join("license", name))
+	if err != nil {
+		return nil, "", err
+	}
+	return b, builtIn, nil
+}
+
+// slhLicenses are the licenses that have an SLH, Standard License Header,
+// which is put in main.go. The rest of the licenses are only copied to the
+// LICENSE file.
+var slhLicenses = map[License]bool{
+	AGPL30:   true,
+	Apache20: true,
+	GPL20:    true,
+	GPL30:    true,
+	LGPL20:   true,
+	LGPL21:   true,
+	LGPL30:   true,
+	MPL20:    true,
+}
+
+// licenseFiles returns the names of the license's files: its full text,
+// followed by its .slh and .cli files. If the license is a version of another
+// license, each file that it doesn't have is that license's.
+func licenseFiles(l License) []string {
+	name := strings.ToLower(l.ID())
+	base := strings.ToLower(l.base().ID())
+	var files []string
+	for _, ext := range []string{"", ".slh", ".cli"} {
+		file := name + ext
+		if base != name {
+			_, _, err := readLicenseFile(file)
+			if os.IsNotExist(err) {
+				file = base + ext
+			}
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// listLicenses writes each supported license's files along with where each
+// file comes from. A missing full text is listed as missing; the .slh and
+// .cli files are optional and are only listed if they exist.
+func listLicenses(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "LICENSE\tFILE\tSOURCE")
+	for _, l := range corpusLicenses {
+		id := l.ID()
+		for i, name := range licenseFiles(l) {
+			_, src, err := readLicenseFile(name)
+			if err != nil {
+				if !os.IsNotExist(err) {
+					return fmt.Errorf("%s: %s", name, err)
+				}
+				if i > 0 { // not all licenses have SLH or CLI files
+					continue
+				}
+				src = "missing"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", id, name, src)
+			id = ""
+		}
+	}
+	return tw.Flush()
+}
+
+// verifyCorpus checks that the license corpus, including any overrides, has
+// what every License in corpusLicenses needs: an entry in the SPDX License
+// List, its full text, an SLH if it's in slhLicenses, a CLI notice, whose
+// sections are in the license, if it has notice flags, and every placeholder
+// in licensePlaceholders is in at least one of the files. It returns the
+// problems that were found, each prefixed with the license's ID.
+func verifyCorpus() ([]string, error) {
+	idx, err := spdxLicenses()
+	if err != nil {
+		return nil, err
+	}
+	var problems []string
+	for _, l := range corpusLicenses {
+		id := l.ID()
+		if _, ok := idx.Lookup(l); !ok {
+			problems = append(problems, fmt.Sprintf("%s: not in the SPDX license list", id))
+		}
+		names := licenseFiles(l)
+		files := make([][]byte, len(names))
+		for i, name := range names {
+			b, _, err := readLicenseFile(name)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("%s: %s", name, err)
+			}
+			files[i] = b
+		}
+		expected := []bool{true, slhLicenses[l.base()], noticeFlags[l.base()] != nil}
+		for i, name := range names {
+			if expected[i] && files[i] == nil {
+				problems = append(problems, fmt.Sprintf("%s: %s: missing", id, name))
+			}
+			if !expected[i] && files[i] != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s: not expected", id, name))
+			}
+		}
+		if files[2] != nil {
+			a := App{License: l}
+			_, err := a.notice()
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s", id, err))
+			}
+		}
+		ps, ok := licensePlaceholders[l.base()]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: the placeholders are not defined", id))
+		}
+		for _, p := range ps {
+			var found bool
+			for _, b := range files {
+				found = found || bytes.Contains(b, []byte(p.Token))
+			}
+			if !found {
+				problems = append(problems, fmt.Sprintf("%s: placeholder %s: not in any of the files", id, p.Token))
+			}
+		}
+	}
+	return problems, nil
+}
+
+// listSPDX writes the licenses in the SPDX License List: their identifier,
+// whether they are OSI approved, FSF libre, or deprecated, whether their full
+// text is in the license corpus, and their name.
+func listSPDX(w io.Writer) error {
+	idx, err := spdxLicenses()
+	if err != nil {
+		return err
+	}
+	yes := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return ""
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tOSI\tFSF\tDEPRECATED\tTEXT\tNAME")
+	for _, l := range idx.licenses {
+		_, _, err := readLicenseFile(strings.ToLower(l.ID))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("%s: %s", l.ID, err)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", l.ID, yes(l.OSI), yes(l.FSF), yes(l.Deprecated), yes(err == nil), l.Name)
+	}
+	return tw.Flush()
+}
+
+// licensesMain runs the licenses command: quine licenses list, quine licenses
+// spdx, quine licenses verify, quine licenses deps, or quine licenses check.
+func licensesMain(args []string) int {
+	if len(args) == 0 {
+		log.Print("licenses: no subcommand; use list, spdx, verify, deps, or check")
+		return 2
+	}
+	switch args[0] {
+	case "list":
+		err := listLicenses(os.Stdout)
+		if err != nil {
+			log.Printf("licenses list: error: %s", err)
+			return 1
+		}
+		return 0
+	case "spdx":
+		err := listSPDX(os.Stdout)
+		if err != nil {
+			log.Printf("licenses spdx: error: %s", err)
+			return 1
+		}
+		return 0
+	case "verify":
+		problems, err := verifyCorpus()
+		if err != nil {
+			log.Printf("licenses verify: error: %s", err)
+			return 1
+		}
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		if len(problems) > 0 {
+			return 1
+		}
+		fmt.Println("ok")
+		return 0
+	case "deps":
+		return depsMain(args[1:])
+	case "check":
+		return checkMain(args[1:])
+	default:
+		log.Printf("licenses: unknown subcommand: %s", args[0])
+		return 2
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadLicenseFile(t *testing.T) {
+	qp, ld := quinePath, licenseDir
+	defer func() {
+		quinePath, licenseDir = qp, ld
+	}()
+	dir, err := ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	err = ioutil.WriteFile(filepath.Join(dir, "mit"), []byte("override"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no override dir: everything is built-in
+	quinePath, licenseDir = "", ""
+	b, src, err := readLicenseFile("mit")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if src != builtIn {
+		t.Errorf("source: got %q want %q", src, builtIn)
+	}
+	if !bytes.HasPrefix(b, []byte("MIT License")) {
+		t.Errorf("got %q", b[:20])
+	}
+
+	// an overridden file comes from the override dir; the rest are built-in
+	licenseDir = dir
+	b, src, err = readLicenseFile("mit")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if src != filepath.Join(dir, "mit") {
+		t.Errorf("source: got %q want %q", src, filepath.Join(dir, "mit"))
+	}
+	if string(b) != "override" {
+		t.Errorf("got %q want %q", b, "override")
+	}
+	_, src, err = readLicenseFile("mpl-2.0.slh")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if src != builtIn {
+		t.Errorf("source: got %q want %q", src, builtIn)
+	}
+
+	_, _, err = readLicenseFile("mit.slh")
+	if !os.IsNotExist(err) {
+		t.Errorf("got %v want a not exist error", err)
+	}
+
+	var buf bytes.Buffer
+	err = listLicenses(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, line := range []string{
+		"MIT                mit                    " + filepath.Join(dir, "mit"),
+		"GPL-3.0            gpl-3.0                built-in",
+		"                   gpl-3.0.cli            built-in",
+		"GPL-3.0-or-later   gpl-3.0                built-in",
+		"                   gpl-3.0-or-later.slh   built-in",
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("list: %q not found in\n%s", line, buf.String())
+		}
+	}
+}
+
+func TestVerifyCorpus(t *testing.T) {
+	problems, err := verifyCorpus()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, p := range problems {
+		t.Errorf("built-in corpus: %s", p)
+	}
+
+	ld := licenseDir
+	defer func() {
+		licenseDir = ld
+	}()
+	dir, err := ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"mit":         "Copyright (c) <year> <owner>\n",
+		"mit.slh":     "Copyright (c) <year>\n",
+		"gpl-2.0.cli": "<program>  Copyright (C) <year>  <name of author>\n",
+		"gpl-3.0":     "GNU GENERAL PUBLIC LICENSE\n",
+	}
+	for name, s := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte(s), 0664)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	licenseDir = dir
+	problems, err = verifyCorpus()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []string{
+		`GPL-3.0: CLI notice: flag show-w: "15. Disclaimer of Warranty." is not in the license`,
+		// the versions of GPL-3.0 use its text
+		`GPL-3.0-only: CLI notice: flag show-w: "15. Disclaimer of Warranty." is not in the license`,
+		`GPL-3.0-or-later: CLI notice: flag show-w: "15. Disclaimer of Warranty." is not in the license`,
+		"MIT: mit.slh: not expected",
+		"MIT: placeholder <copyright holders>: not in any of the files",
+	}
+	if strings.Join(problems, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("got %q\nwant %q", problems, expected)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The files that the licenses deps command writes to the module's root.
+const (
+	depsReportFile  = "third_party_licenses.json"
+	depsNoticesFile = "THIRD_PARTY_NOTICES"
+)
+
+// noAssertion is the license of a license file that couldn't be identified;
+// it's what SPDX uses for a license that isn't known.
+const noAssertion = "NOASSERTION"
+
+// depsReport is the report of the licenses of a module's dependencies, as it's
+// written to depsReportFile.
+type depsReport struct {
+	Module       string       `json:"module"`
+	Dependencies []dependency `json:"dependencies"`
+}
+
+// dependency is a module that is required by the go.mod, along with its
+// license files.
+type dependency struct {
+	Path     string       `json:"path"`
+	Version  string       `json:"version"`
+	Indirect bool         `json:"indirect,omitempty"`
+	Sum      string       `json:"sum,omitempty"` // the module's hash in the go.sum
+	Licenses []depLicense `json:"licenses"`
+	Note     string       `json:"note,omitempty"` // why there are no licenses
+	texts    [][]byte     // the text of each license file, for the notices
+}
+
+// depLicense is a license file of a dependency and the license that it was
+// identified as.
+type depLicense struct {
+	File       string  `json:"file"`
+	License    string  `json:"license"` // the SPDX ID, with any exception, or noAssertion if it wasn't identified
+	Confidence float64 `json:"confidence"`
+}
+
+// isLicenseFile returns whether the file name is that of a license file,
+// e.g. LICENSE, LICENSE.txt, LICENSE-MIT, or COPYING.
+func isLicenseFile(name string) bool {
+	name = strings.ToLower(name)
+	for _, prefix := range []string{"license", "licence", "copying", "unlicense"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencyLicenses returns the report of the licenses of the dependencies
+// of the module in root: each module that its go.mod requires is looked for
+// in the module cache and the license files in its root, along with any
+// exception that follows a license's text, are identified using the license
+// corpus. Replace directives are ignored.
+func dependencyLicenses(root string) (depsReport, error) {
+	mod, reqs, err := readGoMod(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return depsReport{}, fmt.Errorf("read go.mod: %s", err)
+	}
+	sums, err := readGoSum(filepath.Join(root, "go.sum"))
+	if err != nil && !os.IsNotExist(err) { // a module without dependencies doesn't have a go.sum
+		return depsReport{}, fmt.Errorf("read go.sum: %s", err)
+	}
+	m, err := newLicenseMatcher()
+	if err != nil {
+		return depsReport{}, err
+	}
+
+	r := depsReport{Module: mod, Dependencies: []dependency{}}
+	cache := moduleCacheDir()
+	for _, req := range reqs {
+		d := dependency{Path: req.Path, Version: req.Version, Indirect: req.Indirect, Sum: sums[req.Path+" "+req.Version], Licenses: []depLicense{}}
+		dir, ok := moduleDir(cache, req)
+		if !ok {
+			d.Note = "not in the module cache; run go mod download"
+			r.Dependencies = append(r.Dependencies, d)
+			continue
+		}
+		fis, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return depsReport{}, fmt.Errorf("%s %s: %s", req.Path, req.Version, err)
+		}
+		for _, fi := range fis {
+			if fi.IsDir() || !isLicenseFile(fi.Name()) {
+				continue
+			}
+			b, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+			if err != nil {
+				return depsReport{}, fmt.Errorf("%s %s: %s", req.Path, req.Version, err)
+			}
+			l, conf := m.Match(b)
+			id := string(l)
+			if l == None {
+				id = noAssertion
+			} else if x, _ := m.Exception(b); x != "" {
+				id += " " + opWith + " " + x
+			}
+			d.Licenses = append(d.Licenses, depLicense{File: fi.Name(), License: id, Confidence: math.Round(conf*1000) / 1000})
+			d.texts = append(d.texts, b)
+		}
+		if len(d.Licenses) == 0 {
+			d.Note = "no license file"
+		}
+		r.Dependencies = append(r.Dependencies, d)
+	}
+	sort.SliceStable(r.Dependencies, func(i, j int) bool { return r.Dependencies[i].Path < r.Dependencies[j].Path })
+	return r, nil
+}
+
+// notices returns the third-party notices: each dependency followed by the
+// text of its license files.
+func (r depsReport) notices() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s uses the following third-party modules; their licenses follow.\n", r.Module)
+	for _, d := range r.Dependencies {
+		title := d.Path + " " + d.Version
+		fmt.Fprintf(&buf, "\n%s\n%s\n", title, strings.Repeat("=", len(title)))
+		if d.Note != "" {
+			fmt.Fprintf(&buf, "The license is unknown: %s.\n", d.Note)
+			continue
+		}
+		for i, l := range d.Licenses {
+			fmt.Fprintf(&buf, "\n%s: %s\n\n%s\n", l.File, l.License, bytes.TrimSpace(d.texts[i]))
+		}
+	}
+	return buf.Bytes()
+}
+
+// depsOps returns the ops of the files that the licenses deps command writes
+// to the module in root: the report and the notices.
+func depsOps(root string) ([]fileOp, error) {
+	r, err := dependencyLicenses(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range r.Dependencies {
+		if d.Note != "" {
+			log.Printf("warning: %s %s: %s", d.Path, d.Version, d.Note)
+		}
+		for _, l := range d.Licenses {
+			if l.License == noAssertion {
+				log.Printf("warning: %s %s: %s: the license wasn't identified", d.Path, d.Version, l.File)
+			}
+		}
+	}
+	b, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", depsReportFile, err)
+	}
+	report, err := generatedOp(filepath.Join(root, depsReportFile), append(b, '\n'))
+	if err != nil {
+		return nil, err
+	}
+	notices, err := generatedOp(filepath.Join(root, depsNoticesFile), r.notices())
+	if err != nil {
+		return nil, err
+	}
+	return []fileOp{report, notices}, nil
+}
+
+// depsMain runs the licenses deps command: quine [flags] licenses deps [dir].
+// The licenses of the dependencies of the module in dir, or, if it isn't
+// specified, the module that the app's path is in, are written to the module's
+// depsReportFile and depsNoticesFile. With -dry-run, nothing is written; with
+// -diff, the diffs of the files are printed instead.
+func depsMain(args []string) int {
+	if len(args) > 1 || len(args) == 1 && strings.HasPrefix(args[0], "-") {
+		log.Printf("licenses deps: usage: %s [flags] licenses deps [dir]; the flags go before the command", exe)
+		return 2
+	}
+	parseFlags()
+	root := app.root()
+	if len(args) > 0 {
+		root = args[0]
+	}
+
+	ops, err := depsOps(root)
+	if err != nil {
+		log.Printf("licenses deps: error: %s", err)
+		return 1
+	}
+	if app.Diff {
+		return diffOps(ops)
+	}
+	for _, op := range ops {
+		if app.DryRun {
+			op.report(os.Stdout, app.Show)
+			continue
+		}
+		err = op.write()
+		if err != nil {
+			log.Printf("licenses deps: %s: error: %s", op.Path, err)
+			return 1
+		}
+	}
+	return 0
+}
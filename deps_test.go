@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDepsOps(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	mc := os.Getenv("GOMODCACHE")
+	defer os.Setenv("GOMODCACHE", mc)
+	os.Setenv("GOMODCACHE", filepath.Join(dir, "mod"))
+
+	mit, _, err := readLicenseFile("mit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	apache, _, err := readLicenseFile("apache-2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"app/go.mod": "module example.com/hoopy\n\nrequire (\n\tgithub.com/Zaphod/heart v1.0.0\n\texample.com/towel v0.2.0 // indirect\n\texample.com/missing v0.1.0\n\texample.com/unlicensed v1.1.0\n)\n",
+		"app/go.sum": "example.com/towel v0.2.0 h1:towel=\nexample.com/towel v0.2.0/go.mod h1:towelmod=\n",
+		"mod/github.com/!zaphod/heart@v1.0.0/LICENSE":    strings.Replace(string(mit), "<year> <copyright holders>", "1978 Zaphod", 1),
+		"mod/example.com/towel@v0.2.0/LICENSE-APACHE":    string(apache),
+		"mod/example.com/towel@v0.2.0/LICENSE-OTHER":     "Do what you want with it.\n",
+		"mod/example.com/towel@v0.2.0/licensing/LICENSE": string(mit),
+		"mod/example.com/unlicensed@v1.1.0/README.md":    "Towels.\n",
+	}
+	for name, s := range files {
+		file := filepath.Join(dir, name)
+		err = os.MkdirAll(filepath.Dir(file), 0775)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(file, []byte(s), 0664)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	root := filepath.Join(dir, "app")
+	ops, err := depsOps(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(ops) != 2 {
+		t.Fatalf("got %d ops want 2", len(ops))
+	}
+	if ops[0].Path != filepath.Join(root, depsReportFile) || ops[0].Action != actionCreate {
+		t.Errorf("got %s %s want create %s", ops[0].Action, ops[0].Path, depsReportFile)
+	}
+	var r depsReport
+	err = json.Unmarshal(ops[0].Data, &r)
+	if err != nil {
+		t.Fatalf("unmarshal report: %s", err)
+	}
+	if r.Module != "example.com/hoopy" {
+		t.Errorf("module: got %q want %q", r.Module, "example.com/hoopy")
+	}
+	expected := []struct {
+		path     string
+		indirect bool
+		sum      string
+		licenses []string
+		note     string
+	}{
+		{"example.com/missing", false, "", nil, "not in the module cache; run go mod download"},
+		{"example.com/towel", true, "h1:towel=", []string{"LICENSE-APACHE: Apache-2.0", "LICENSE-OTHER: NOASSERTION"}, ""},
+		{"example.com/unlicensed", false, "", nil, "no license file"},
+		{"github.com/Zaphod/heart", false, "", []string{"LICENSE: MIT"}, ""},
+	}
+	if len(r.Dependencies) != len(expected) {
+		t.Fatalf("got %d dependencies want %d", len(r.Dependencies), len(expected))
+	}
+	for i, d := range r.Dependencies {
+		e := expected[i]
+		if d.Path != e.path || d.Indirect != e.indirect || d.Sum != e.sum || d.Note != e.note {
+			t.Errorf("%d: got %s %t %q %q want %s %t %q %q", i, d.Path, d.Indirect, d.Sum, d.Note, e.path, e.indirect, e.sum, e.note)
+		}
+		var licenses []string
+		for _, l := range d.Licenses {
+			licenses = append(licenses, l.File+": "+l.License)
+		}
+		if strings.Join(licenses, ", ") != strings.Join(e.licenses, ", ") {
+			t.Errorf("%s: got %v want %v", d.Path, licenses, e.licenses)
+		}
+	}
+
+	if ops[1].Path != filepath.Join(root, depsNoticesFile) {
+		t.Errorf("got %s want %s", ops[1].Path, depsNoticesFile)
+	}
+	notices := string(ops[1].Data)
+	for _, s := range []string{
+		"example.com/hoopy uses the following third-party modules; their licenses follow.\n",
+		"\nexample.com/missing v0.1.0\n==========================\nThe license is unknown: not in the module cache; run go mod download.\n",
+		"\nLICENSE-OTHER: NOASSERTION\n\nDo what you want with it.\n",
+		"\nLICENSE: MIT\n\nMIT License\nCopyright (c) 1978 Zaphod\n",
+	} {
+		if !strings.Contains(notices, s) {
+			t.Errorf("notices: %q isn't in %q", s, notices)
+		}
+	}
+
+	os.Remove(filepath.Join(root, "go.sum")) // it's optional
+	_, err = depsOps(root)
+	if err != nil {
+		t.Errorf("no go.sum: unexpected error: %s", err)
+	}
+	_, err = depsOps(dir)
+	if err == nil || !strings.HasPrefix(err.Error(), "read go.mod: ") {
+		t.Errorf("got %v want a read go.mod error", err)
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// licenseFileNames are the names of the license file that detectLicense
+// looks for, in order.
+var licenseFileNames = []string{"LICENSE", "LICENSE.txt", "LICENSE.md", "COPYING", "COPYING.txt"}
+
+// spdxIDRe matches an SPDX-License-Identifier tag.
+var spdxIDRe = regexp.MustCompile(`SPDX-License-Identifier:\s*(\S+)`)
+
+// spdxExprRe matches an SPDX-License-Identifier tag and the whole license
+// expression on its line.
+var spdxExprRe = regexp.MustCompile(`(?m)SPDX-License-Identifier:[ \t]*(.*?)[ \t]*(?:\*/)?[ \t]*$`)
+
+// detectedLicense is a license that was detected in a license file.
+type detectedLicense struct {
+	License    License // None if the file's license wasn't identified
+	File       string
+	Closest    License // the closest match, even if its confidence is too low
+	Confidence float64
+}
+
+// findLicenseFile returns the path of the app's license file: the first of
+// licenseFileNames in the app's path or, if there isn't one, in the root of
+// the app's module. If there isn't a license file, an empty string is
+// returned.
+func (a *App) findLicenseFile() (string, error) {
+	dirs := []string{a.Path}
+	if root := a.root(); root != a.Path {
+		dirs = append(dirs, root)
+	}
+	for _, dir := range dirs {
+		for _, name := range licenseFileNames {
+			file := filepath.Join(dir, name)
+			_, err := os.Stat(file)
+			if err == nil {
+				return file, nil
+			}
+			if !os.IsNotExist(err) {
+				return "", err
+			}
+		}
+	}
+	return "", nil
+}
+
+// detectLicense identifies the license of the app's license file, see
+// findLicenseFile, by matching it against the license corpus. The app's
+// copyright holders, year, and name are removed from the file first: they are
+// what quine replaced the license's placeholders with. If there isn't a
+// license file, nil is returned.
+func (a *App) detectLicense() (*detectedLicense, error) {
+	file, err := a.findLicenseFile()
+	if err != nil || file == "" {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range a.holders() {
+		b = bytes.Replace(b, []byte(h.Name), nil, -1)
+	}
+	for _, v := range []string{a.Year, a.Name} { // only whole words: the name may be a common word
+		if v != "" {
+			b = regexp.MustCompile(`\b`+regexp.QuoteMeta(v)+`\b`).ReplaceAll(b, nil)
+		}
+	}
+	m, err := newLicenseMatcher()
+	if err != nil {
+		return nil, err
+	}
+	d := detectedLicense{File: file}
+	d.Closest, d.Confidence = m.closest(b)
+	if d.Confidence >= matchThreshold {
+		d.License, err = a.licenseVersion(d.Closest)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &d, nil
+}
+
+// licenseVersion returns the version of the license, l, that the header of
+// the app's main.go is for: the -only and -or-later versions of a license
+// have the same text, so a license file can't tell them apart. The header's
+// SPDX-License-Identifier is used if it's a version of l; otherwise, an SLH
+// that allows any later version is the -or-later license. If l doesn't have
+// versions, or main.go doesn't say, l is returned.
+func (a *App) licenseVersion(l License) (License, error) {
+	var versions []License
+	for _, v := range corpusLicenses {
+		if v != l && v.base() == l {
+			versions = append(versions, v)
+		}
+	}
+	if len(versions) == 0 {
+		return l, nil
+	}
+	b, err := ioutil.ReadFile(filepath.Join(a.Path, mainFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return l, nil
+		}
+		return None, err
+	}
+	blocks, _ := leadingComments(b)
+	for _, c := range blocks {
+		if m := spdxIDRe.FindStringSubmatch(c.text); m != nil {
+			for _, v := range versions {
+				if m[1] == v.ID() {
+					return v, nil
+				}
+			}
+		}
+		text := strings.Join(strings.Fields(strings.Replace(c.text, "//", " ", -1)), " ")
+		if !strings.Contains(text, "any later version") {
+			continue
+		}
+		for _, v := range versions {
+			if strings.HasSuffix(v.ID(), "-or-later") {
+				return v, nil
+			}
+		}
+	}
+	return l, nil
+}
+
+// detectExpression returns the license expression of the
+// SPDX-License-Identifier in the header of the app's main.go. A project that
+// is licensed under an expression doesn't have a license file: it has a
+// LICENSE-<ID> file for each of the expression's licenses and exceptions. If
+// main.go doesn't have the tag, nil is returned.
+func (a *App) detectExpression() (*LicenseExpr, error) {
+	b, err := ioutil.ReadFile(filepath.Join(a.Path, mainFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	blocks, _ := leadingComments(b)
+	for _, c := range blocks {
+		if m := spdxExprRe.FindStringSubmatch(c.text); m != nil {
+			expr, err := ParseLicenseExpr(m[1])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", mainFile, err)
+			}
+			return expr, nil
+		}
+	}
+	return nil, nil
+}
+
+// defaultLicense sets the app's license, if it isn't set, to the license that
+// is detected in the app's license file or, if it doesn't have one, to the
+// license expression of its main.go; see detectExpression. The detected
+// license, or why one wasn't, is logged. If neither is detected but the app
+// has LICENSE-<ID> files, an error is returned: regenerating it without a
+// license would orphan them.
+func (a *App) defaultLicense() error {
+	d, err := a.detectLicense()
+	if err != nil {
+		return fmt.Errorf("detect license: %s", err)
+	}
+	if d == nil { // no license file
+		expr, err := a.detectExpression()
+		if err != nil {
+			return fmt.Errorf("detect license: %s", err)
+		}
+		if expr != nil {
+			if expr.IsLicense() {
+				a.License = expr.License
+			} else {
+				a.Expression = expr
+			}
+			log.Printf("%s: detected %s from its SPDX-License-Identifier; use -license to override it", filepath.Join(a.Path, mainFile), expr)
+			return nil
+		}
+		files, err := filepath.Glob(filepath.Join(a.Path, exprFile("*")))
+		if err != nil {
+			return fmt.Errorf("detect license: %s", err)
+		}
+		if len(files) > 0 {
+			return fmt.Errorf("detect license: %s: the license wasn't detected; use -license to set it", files[0])
+		}
+		return nil
+	}
+	if d.License == None {
+		if d.Confidence < 0.5 { // it's not much of a match
+			log.Printf("warning: %s: the license wasn't detected; use -license to set it", d.File)
+			return nil
+		}
+		log.Printf("warning: %s: the license wasn't detected: the closest match is %s, with a confidence of %.2f; use -license to set it", d.File, d.Closest, d.Confidence)
+		return nil
+	}
+	a.License = d.License
+	log.Printf("%s: detected %s with a confidence of %.2f; use -license to override it", d.File, d.License, d.Confidence)
+	return nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectLicense(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	lapp := app
+	lapp.Name = "hoopy"
+	lapp.Owner = "Trillian Astra"
+	lapp.Year = "1999"
+	lapp.Commands = nil
+
+	tests := []struct {
+		name     string
+		license  License
+		header   string
+		expected License
+	}{
+		{"mit", MIT, headerSLH, MIT},
+		{"apache", Apache20, headerSLH, Apache20},
+		{"bsd-3", BSD3Clause, headerNone, BSD3Clause},
+		{"gpl-3.0-only", GPL30Only, headerSLH, GPL30},
+		{"gpl-3.0-or-later", GPL30OrLater, headerSLH, GPL30OrLater},
+		{"gpl-2.0-only spdx", GPL20Only, headerSPDX, GPL20Only},
+		{"lgpl-2.1-or-later spdx", LGPL21OrLater, headerSPDX, LGPL21OrLater},
+		{"gpl-2.0 no header", GPL20OrLater, headerNone, GPL20},
+	}
+	for _, test := range tests {
+		lapp.Path = filepath.Join(dir, test.name)
+		lapp.License, lapp.Header = test.license, test.header
+		ops, err := lapp.Plan()
+		if err != nil {
+			t.Fatalf("%s: plan: %s", test.name, err)
+		}
+		for _, op := range ops {
+			err = op.write()
+			if err != nil {
+				t.Fatalf("%s: %s", test.name, err)
+			}
+		}
+		lapp.License = None
+		d, err := lapp.detectLicense()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if d == nil {
+			t.Errorf("%s: the license file wasn't found", test.name)
+			continue
+		}
+		if d.License != test.expected {
+			t.Errorf("%s: got %q (%.3f) want %q", test.name, d.License, d.Confidence, test.expected)
+		}
+		if d.File != filepath.Join(lapp.Path, "LICENSE") {
+			t.Errorf("%s: got %s want its LICENSE", test.name, d.File)
+		}
+	}
+
+	// no license file
+	lapp.Path = filepath.Join(dir, "none")
+	d, err := lapp.detectLicense()
+	if err != nil || d != nil {
+		t.Errorf("no license file: got %v, %v want nil, nil", d, err)
+	}
+
+	// a COPYING file in the module's root
+	root := filepath.Join(dir, "module")
+	lapp.Path = filepath.Join(root, "cmd", lapp.Name)
+	lapp.CmdDir = true
+	defer func() { lapp.CmdDir = false }()
+	b, _, err := readLicenseFile("isc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{"go.mod": []byte("module example.com/hoopy\n"), "COPYING": b} {
+		err = os.MkdirAll(root, 0775)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join(root, name), data, 0664)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	d, err = lapp.detectLicense()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if d == nil || d.License != ISC || d.File != filepath.Join(root, "COPYING") {
+		t.Errorf("got %+v want ISC in the module's COPYING", d)
+	}
+
+	// not a license
+	err = ioutil.WriteFile(filepath.Join(root, "COPYING"), []byte("Copy what you like.\n"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err = lapp.detectLicense()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if d == nil || d.License != None || d.Confidence >= matchThreshold {
+		t.Errorf("got %+v want no license", d)
+	}
+}
+
+func TestDefaultLicenseExpression(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	lapp := app
+	lapp.Name = "hoopy"
+	lapp.Owner = "Trillian Astra"
+	lapp.Year = "1999"
+	lapp.Commands = nil
+	lapp.Path = dir
+	lapp.Expression, err = ParseLicenseExpr("MIT OR Apache-2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := func(a App) {
+		ops, err := a.Plan()
+		if err != nil {
+			t.Fatalf("plan: %s", err)
+		}
+		for _, op := range ops {
+			err = op.write()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	write(lapp)
+
+	// regenerate it without a license: the expression is detected from main.go
+	lapp.License, lapp.Expression = None, nil
+	err = lapp.defaultLicense()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if lapp.Expression == nil || lapp.Expression.String() != "MIT OR Apache-2.0" {
+		t.Fatalf("got %v want MIT OR Apache-2.0", lapp.Expression)
+	}
+	ops, err := lapp.Plan()
+	if err != nil {
+		t.Fatalf("plan: %s", err)
+	}
+	files := map[string]string{}
+	for _, op := range ops {
+		files[filepath.Base(op.Path)] = string(op.Data)
+	}
+	for _, name := range []string{"LICENSE-MIT", "LICENSE-Apache-2.0"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("%s isn't managed", name)
+		}
+	}
+	if !strings.Contains(files[mainFile], "SPDX-License-Identifier: MIT OR Apache-2.0\n") {
+		t.Errorf("main.go's header doesn't have the expression:\n%s", files[mainFile])
+	}
+
+	// a single license in main.go's header, without a license file
+	err = ioutil.WriteFile(filepath.Join(dir, mainFile), []byte("// SPDX-License-Identifier: MIT\n\npackage main\n"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lapp.License, lapp.Expression = None, nil
+	err = lapp.defaultLicense()
+	if err != nil || lapp.License != MIT || lapp.Expression != nil {
+		t.Errorf("got %q, %v, %v want MIT", lapp.License, lapp.Expression, err)
+	}
+
+	// nothing to detect it from, but there are LICENSE-<ID> files
+	err = ioutil.WriteFile(filepath.Join(dir, mainFile), []byte("package main\n"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lapp.License, lapp.Expression = None, nil
+	err = lapp.defaultLicense()
+	if err == nil || !strings.HasSuffix(err.Error(), "the license wasn't detected; use -license to set it") {
+		t.Errorf("got %v want a license wasn't detected error", err)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around each change in a
+// unified diff.
+const diffContext = 3
+
+// diffLine is a line of a diff: kind is ' ' for an unchanged line, '-' for a
+// removed line, and '+' for an added line. The text includes the line's
+// newline, unless it's the last line and the file doesn't end with one.
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// diff writes a unified diff of the file on disk and the op's rendered file
+// to w and returns whether they differ. User-owned files are not compared: a
+// user-owned file that exists is never written and the rest of them are only
+// written once. A file that doesn't exist is diffed against /dev/null.
+func (op fileOp) diff(w io.Writer) (bool, error) {
+	if op.User {
+		return false, nil
+	}
+	name := "/dev/null"
+	var b []byte
+	if op.Action == actionOverwrite {
+		var err error
+		b, err = ioutil.ReadFile(op.Path)
+		if err != nil {
+			return false, err
+		}
+		name = op.Path
+	}
+	d := unifiedDiff(name, op.Path, b, op.Data)
+	if d == "" {
+		return false, nil
+	}
+	_, err := io.WriteString(w, d)
+	return true, err
+}
+
+// diffOps writes the unified diffs of the ops' files to os.Stdout. It returns
+// 1 if any of the files differ, which includes any that don't exist, so that
+// it can be used to check that the generated files are up to date.
+func diffOps(ops []fileOp) int {
+	code := 0
+	for _, op := range ops {
+		differs, err := op.diff(os.Stdout)
+		if err != nil {
+			log.Printf("%s: diff: error: %s", op.Path, err)
+			return 1
+		}
+		if differs {
+			code = 1
+		}
+	}
+	return code
+}
+
+// unifiedDiff returns the unified diff of a and b, which are named oldName
+// and newName in the header. An empty string is returned if they are the
+// same.
+func unifiedDiff(oldName, newName string, a, b []byte) string {
+	d := diffLines(splitLines(string(a)), splitLines(string(b)))
+
+	// the position, in the old and new files, of each line of the diff.
+	oldPos := make([]int, len(d)+1)
+	newPos := make([]int, len(d)+1)
+	for i, l := range d {
+		oldPos[i+1], newPos[i+1] = oldPos[i], newPos[i]
+		if l.kind != '+' {
+			oldPos[i+1]++
+		}
+		if l.kind != '-' {
+			newPos[i+1]++
+		}
+	}
+
+	var buf strings.Builder
+	for i := 0; i < len(d); i++ {
+		if d[i].kind == ' ' {
+			continue
+		}
+		if buf.Len() == 0 {
+			fmt.Fprintf(&buf, "--- %s\n+++ %s\n", oldName, newName)
+		}
+		// the hunk includes every change that is within 2*diffContext
+		// lines of the previous one.
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for {
+			for end < len(d) && d[end].kind != ' ' {
+				end++
+			}
+			next := end
+			for next < len(d) && d[next].kind == ' ' {
+				next++
+			}
+			if next == len(d) || next-end > 2*diffContext {
+				break
+			}
+			end = next
+		}
+		end += diffContext
+		if end > len(d) {
+			end = len(d)
+		}
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(oldPos[start], oldPos[end]), hunkRange(newPos[start], newPos[end]))
+		for _, l := range d[start:end] {
+			buf.WriteByte(l.kind)
+			buf.WriteString(l.text)
+			if !strings.HasSuffix(l.text, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end - 1
+	}
+	return buf.String()
+}
+
+// hunkRange returns the range of a hunk header for the lines from, to, which
+// are 0 based: the 1 based start followed by the number of lines, if it
+// isn't 1. An empty range starts at the line before it.
+func hunkRange(from, to int) string {
+	switch to - from {
+	case 0:
+		return fmt.Sprintf("%d,0", from)
+	case 1:
+		return fmt.Sprintf("%d", from+1)
+	default:
+		return fmt.Sprintf("%d,%d", from+1, to-from)
+	}
+}
+
+// splitLines splits s into lines; each line includes its newline.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the lines of a and b as a diff, using their longest
+// common subsequence. Any common prefix and suffix are trimmed first, as the
+// files that are diffed usually only have a few changes.
+func diffLines(a, b []string) []diffLine {
+	var pre, suf []diffLine
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		pre = append(pre, diffLine{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suf = append([]diffLine{{' ', a[len(a)-1]}}, suf...)
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	d := pre
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			d = append(d, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			d = append(d, diffLine{'-', a[i]})
+			i++
+		default:
+			d = append(d, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		d = append(d, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		d = append(d, diffLine{'+', b[j]})
+	}
+	return append(d, suf...)
+}
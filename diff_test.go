@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected string
+	}{
+		{"a\nb\n", "a\nb\n", ""},
+		{"", "", ""},
+		{
+			"a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn\n",
+			"a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nn\nextra",
+			"--- old\n+++ new\n" +
+				"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+				"@@ -10,5 +10,5 @@\n j\n k\n l\n-m\n n\n+extra\n\\ No newline at end of file\n",
+		},
+		{ // changes within 2*diffContext lines are in the same hunk
+			"a\nb\nc\nd\ne\nf\ng\nh\n",
+			"A\nb\nc\nd\ne\nf\ng\nH\n",
+			"--- old\n+++ new\n@@ -1,8 +1,8 @@\n-a\n+A\n b\n c\n d\n e\n f\n g\n-h\n+H\n",
+		},
+		{"", "a\nb\n", "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{"x\n", "", "--- old\n+++ new\n@@ -1 +0,0 @@\n-x\n"},
+	}
+	for i, test := range tests {
+		got := unifiedDiff("old", "new", []byte(test.a), []byte(test.b))
+		if got != test.expected {
+			t.Errorf("%d: got %q\nwant %q", i, got, test.expected)
+		}
+	}
+}
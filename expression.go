@@ -0,0 +1,379 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The operators of an SPDX license expression. They are matched
+// case-sensitively, as the SPDX specification recommends, so that a license's
+// name, e.g. BSD 3-Clause "New" or "Revised" License, isn't mistaken for an
+// expression.
+const (
+	opAnd  = "AND"
+	opOr   = "OR"
+	opWith = "WITH"
+)
+
+// LicenseExpr is an SPDX license expression: either a license, along with
+// any exception, or the AND or OR of other expressions.
+type LicenseExpr struct {
+	Op        string         // opAnd or opOr; empty for a license
+	Args      []*LicenseExpr // the operands of Op
+	License   License        // the license, without any + suffix
+	OrLater   bool           // the license, or any later version: +; only if the license doesn't have an -or-later license
+	Exception string         // the SPDX Identifier of the license's exception, if it has one: WITH
+}
+
+// ParseLicenseExpr parses s, an SPDX license expression, e.g. MIT OR
+// Apache-2.0. Each of its licenses may be anything that LicenseFromString
+// accepts, with a + suffix for the license or any later version. WITH binds
+// tighter than AND, which binds tighter than OR; parentheses group.
+//
+// An empty string will result in a nil expression and is not considered an
+// error.
+func ParseLicenseExpr(s string) (*LicenseExpr, error) {
+	p := exprParser{s: s, toks: tokenizeLicenseExpr(s)}
+	if len(p.toks) == 0 { // nothing specified is not an error state.
+		return nil, nil
+	}
+	e, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.i < len(p.toks) {
+		return nil, p.errorf("unexpected %s", p.toks[p.i])
+	}
+	return e, nil
+}
+
+// tokenizeLicenseExpr splits s into its words and parentheses.
+func tokenizeLicenseExpr(s string) []string {
+	var toks []string
+	for _, f := range strings.Fields(s) {
+		for f != "" {
+			i := strings.IndexAny(f, "()")
+			switch {
+			case i < 0:
+				toks = append(toks, f)
+				f = ""
+			case i > 0:
+				toks = append(toks, f[:i])
+				f = f[i:]
+			default:
+				toks = append(toks, f[:1])
+				f = f[1:]
+			}
+		}
+	}
+	return toks
+}
+
+// exprParser is a recursive descent parser of an SPDX license expression.
+type exprParser struct {
+	s    string   // the expression
+	toks []string // its tokens
+	i    int      // the index of the next token
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("license expression %q: %s", p.s, fmt.Sprintf(format, args...))
+}
+
+// peek returns the next token; an empty string if there aren't any more.
+func (p *exprParser) peek() string {
+	if p.i < len(p.toks) {
+		return p.toks[p.i]
+	}
+	return ""
+}
+
+// words returns the tokens up to the next operator or parenthesis. A license
+// may be more than one word, e.g. its full name.
+func (p *exprParser) words() []string {
+	var words []string
+	for ; p.i < len(p.toks); p.i++ {
+		switch p.toks[p.i] {
+		case opAnd, opOr, opWith, "(", ")":
+			return words
+		}
+		words = append(words, p.toks[p.i])
+	}
+	return words
+}
+
+func (p *exprParser) or() (*LicenseExpr, error) {
+	return p.binary(opOr, p.and)
+}
+
+func (p *exprParser) and() (*LicenseExpr, error) {
+	return p.binary(opAnd, p.with)
+}
+
+// binary parses the operands of op. Operands that are themselves op, e.g.
+// (A OR B) OR C, are flattened.
+func (p *exprParser) binary(op string, operand func() (*LicenseExpr, error)) (*LicenseExpr, error) {
+	var args []*LicenseExpr
+	for {
+		e, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if e.Op == op {
+			args = append(args, e.Args...)
+		} else {
+			args = append(args, e)
+		}
+		if p.peek() != op {
+			break
+		}
+		p.i++
+	}
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	return &LicenseExpr{Op: op, Args: args}, nil
+}
+
+func (p *exprParser) with() (*LicenseExpr, error) {
+	e, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != opWith {
+		return e, nil
+	}
+	p.i++
+	if e.Op != "" || e.Exception != "" {
+		return nil, p.errorf("WITH must follow a license")
+	}
+	words := p.words()
+	if len(words) == 0 {
+		return nil, p.errorf("WITH must be followed by an exception")
+	}
+	idx, err := spdxLicenses()
+	if err != nil {
+		return nil, err
+	}
+	x, ok := idx.LookupException(strings.Join(words, " "))
+	if !ok {
+		return nil, p.errorf("unsupported exception: %s", strings.Join(words, " "))
+	}
+	e.Exception = x.ID
+	return e, nil
+}
+
+func (p *exprParser) term() (*LicenseExpr, error) {
+	switch p.peek() {
+	case "":
+		return nil, p.errorf("missing license")
+	case "(":
+		p.i++
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, p.errorf("missing )")
+		}
+		p.i++
+		return e, nil
+	}
+	words := p.words()
+	if len(words) == 0 {
+		return nil, p.errorf("unexpected %s", p.peek())
+	}
+	return licenseTerm(strings.Join(words, " "))
+}
+
+// licenseTerm returns the expression for s, a license with any suffix. A
+// license with a + suffix is its -or-later license, if it has one, e.g.
+// GPL-2.0+ is GPL-2.0-or-later.
+func licenseTerm(s string) (*LicenseExpr, error) {
+	l, err := LicenseFromString(s)
+	if err == nil {
+		// the deprecated + IDs, e.g. GPL-2.0+.
+		id := strings.TrimSuffix(string(l), "+")
+		if id != string(l) && inSPDX(id+"-or-later") {
+			l = License(id + "-or-later")
+		}
+		return &LicenseExpr{License: l}, nil
+	}
+	// the + suffix can be added to any license.
+	if !strings.HasSuffix(s, "+") {
+		return nil, err
+	}
+	l, err = LicenseFromString(strings.TrimSuffix(s, "+"))
+	if err != nil {
+		return nil, UnsupportedLicenseErr{s}
+	}
+	if inSPDX(string(l) + "-or-later") {
+		return &LicenseExpr{License: l + "-or-later"}, nil
+	}
+	return &LicenseExpr{License: l, OrLater: true}, nil
+}
+
+// inSPDX returns whether id is in the SPDX License List.
+func inSPDX(id string) bool {
+	idx, err := spdxLicenses()
+	if err != nil {
+		return false
+	}
+	_, ok := idx.Lookup(License(id))
+	return ok
+}
+
+// IsLicense returns whether the expression is just a license: it has no
+// operator, suffix, or exception.
+func (e *LicenseExpr) IsLicense() bool {
+	return e.Op == "" && !e.OrLater && e.Exception == ""
+}
+
+// ID returns the SPDX Identifier of the expression's license along with its
+// suffix.
+func (e *LicenseExpr) ID() string {
+	if e.OrLater {
+		return string(e.License) + "+"
+	}
+	return string(e.License)
+}
+
+// String returns the expression in its canonical form. Parentheses are only
+// added where they are needed: around an OR that is an operand of an AND.
+func (e *LicenseExpr) String() string {
+	if e.Op == "" {
+		if e.Exception != "" {
+			return e.ID() + " " + opWith + " " + e.Exception
+		}
+		return e.ID()
+	}
+	args := make([]string, len(e.Args))
+	for i, a := range e.Args {
+		args[i] = a.String()
+		if a.Op == opOr && e.Op == opAnd {
+			args[i] = "(" + args[i] + ")"
+		}
+	}
+	return strings.Join(args, " "+e.Op+" ")
+}
+
+// Licenses returns the licenses in the expression, without their + suffixes,
+// in the order that they first appear.
+func (e *LicenseExpr) Licenses() []License {
+	var ls []License
+	e.walk(func(x *LicenseExpr) {
+		for _, l := range ls {
+			if l == x.License {
+				return
+			}
+		}
+		ls = append(ls, x.License)
+	})
+	return ls
+}
+
+// Exceptions returns the exceptions in the expression in the order that they
+// first appear.
+func (e *LicenseExpr) Exceptions() []string {
+	var xs []string
+	e.walk(func(x *LicenseExpr) {
+		if x.Exception == "" {
+			return
+		}
+		for _, id := range xs {
+			if id == x.Exception {
+				return
+			}
+		}
+		xs = append(xs, x.Exception)
+	})
+	return xs
+}
+
+// walk calls f for each license in the expression.
+func (e *LicenseExpr) walk(f func(*LicenseExpr)) {
+	if e.Op == "" {
+		f(e)
+		return
+	}
+	for _, a := range e.Args {
+		a.walk(f)
+	}
+}
+
+// describe returns the expression in words, using the names of its licenses
+// and exceptions from the SPDX License List. Operands that are themselves an
+// AND or OR are parenthesized.
+func (e *LicenseExpr) describe(idx *spdxIndex) string {
+	if e.Op == "" {
+		name := string(e.License)
+		if sl, ok := idx.Lookup(e.License); ok {
+			name = sl.Name
+		}
+		if e.OrLater {
+			name += " or later"
+		}
+		s := "the " + strings.TrimPrefix(name, "The ")
+		if x, ok := idx.LookupException(e.Exception); ok {
+			s += " with the " + x.Name
+		}
+		return s
+	}
+	args := make([]string, len(e.Args))
+	for i, a := range e.Args {
+		args[i] = a.describe(idx)
+		if a.Op != "" {
+			args[i] = "(" + args[i] + ")"
+		}
+	}
+	if e.Op == opOr {
+		return "either " + listJoin(args, "or")
+	}
+	if len(args) == 2 {
+		return "both " + listJoin(args, "and")
+	}
+	return "all of " + listJoin(args, "and")
+}
+
+// listJoin joins the items as a list in a sentence: a, b, conj c.
+func listJoin(items []string, conj string) string {
+	switch len(items) {
+	case 1:
+		return items[0]
+	case 2:
+		return items[0] + " " + conj + " " + items[1]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + ", " + conj + " " + items[len(items)-1]
+}
+
+// exprFile returns the name of the file that the full text of the
+// expression's license or exception, id, is written to.
+func exprFile(id string) string {
+	return "LICENSE-" + id
+}
+
+// header returns the header of a project whose license is the expression: its
+// copyright, the expression in words along with the files with the full text
+// of its licenses and exceptions, and its SPDX-License-Identifier.
+func (e *LicenseExpr) header() (string, error) {
+	idx, err := spdxLicenses()
+	if err != nil {
+		return "", err
+	}
+	var files []string
+	for _, l := range e.Licenses() {
+		files = append(files, exprFile(l.ID()))
+	}
+	for _, x := range e.Exceptions() {
+		files = append(files, exprFile(x))
+	}
+	terms := e.describe(idx)
+	if e.Op == opOr {
+		terms += ", at your option"
+	}
+	s := "file"
+	if len(files) > 1 {
+		s = "files"
+	}
+	return fmt.Sprintf("Copyright (c) <year> <owner>\n\nThis program is licensed under %s. See the %s %s for the terms.\n\nSPDX-License-Identifier: %s\n", terms, listJoin(files, "and"), s, e), nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLicenseExpr(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+		license  bool
+		err      string
+	}{
+		{"MIT", "MIT", true, ""},
+		{"Apache License 2.0", "Apache-2.0", true, ""},
+		{"BSD-3-Clause New or Revised License", "BSD-3-Clause", true, ""},
+		{"MIT OR Apache-2.0", "MIT OR Apache-2.0", false, ""},
+		{"mit OR apache2", "MIT OR Apache-2.0", false, ""},
+		{"MIT License OR Apache License 2.0", "MIT OR Apache-2.0", false, ""},
+		{"GPL-2.0-or-later WITH Classpath-exception-2.0", "GPL-2.0-or-later WITH Classpath-exception-2.0", false, ""},
+		{"GPL-2.0+ WITH Classpath exception 2.0", "GPL-2.0-or-later WITH Classpath-exception-2.0", false, ""},
+		{"GPL-3.0-only", "GPL-3.0-only", true, ""},
+		{"GPL-3.0+", "GPL-3.0-or-later", true, ""},
+		{"GPL-3.0-or-later", "GPL-3.0-or-later", true, ""},
+		{"MIT+", "MIT+", false, ""},
+		{"(MIT OR ISC) AND GPL-3.0", "(MIT OR ISC) AND GPL-3.0", false, ""},
+		{"(MIT OR ISC) OR (0BSD)", "MIT OR ISC OR 0BSD", false, ""},
+		{"MIT OR ISC AND 0BSD", "MIT OR ISC AND 0BSD", false, ""},
+		{"MIT AND (ISC AND 0BSD)", "MIT AND ISC AND 0BSD", false, ""},
+		{"(MIT)", "MIT", true, ""},
+		{"", "", false, ""},
+		{"fdas", "", false, "unsupported license: fdas"},
+		{"MIT OR fdas", "", false, "unsupported license: fdas"},
+		{"MIT AND", "", false, `license expression "MIT AND": missing license`},
+		{"(MIT OR ISC", "", false, `license expression "(MIT OR ISC": missing )`},
+		{"MIT)", "", false, `license expression "MIT)": unexpected )`},
+		{"MIT WITH", "", false, `license expression "MIT WITH": WITH must be followed by an exception`},
+		{"MIT WITH fdas", "", false, `license expression "MIT WITH fdas": unsupported exception: fdas`},
+		{"(MIT OR ISC) WITH LLVM-exception", "", false, `license expression "(MIT OR ISC) WITH LLVM-exception": WITH must follow a license`},
+	}
+	for _, test := range tests {
+		e, err := ParseLicenseExpr(test.expr)
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%s: got %s; want %s", test.expr, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%s: got no error; want %s", test.expr, test.err)
+			continue
+		}
+		if e == nil {
+			if test.expected != "" {
+				t.Errorf("%s: got nil; want %s", test.expr, test.expected)
+			}
+			continue
+		}
+		if e.String() != test.expected {
+			t.Errorf("%s: got %s; want %s", test.expr, e, test.expected)
+		}
+		if e.IsLicense() != test.license {
+			t.Errorf("%s: is license: got %t; want %t", test.expr, e.IsLicense(), test.license)
+		}
+	}
+}
+
+func TestLicenseExprHeader(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{"MIT OR Apache-2.0", "This program is licensed under either the MIT License or the Apache License 2.0, at your option. See the LICENSE-MIT and LICENSE-Apache-2.0 files for the terms.\n\nSPDX-License-Identifier: MIT OR Apache-2.0\n"},
+		{"GPL-2.0-or-later WITH Classpath-exception-2.0", "This program is licensed under the GNU General Public License v2.0 or later with the Classpath exception 2.0. See the LICENSE-GPL-2.0-or-later and LICENSE-Classpath-exception-2.0 files for the terms.\n\nSPDX-License-Identifier: GPL-2.0-or-later WITH Classpath-exception-2.0\n"},
+		{"(MIT OR ISC) AND Unlicense", "This program is licensed under both (either the MIT License or the ISC License) and the Unlicense. See the LICENSE-MIT, LICENSE-ISC, and LICENSE-Unlicense files for the terms.\n\nSPDX-License-Identifier: (MIT OR ISC) AND Unlicense\n"},
+		{"MIT+", "This program is licensed under the MIT License or later. See the LICENSE-MIT file for the terms.\n\nSPDX-License-Identifier: MIT+\n"},
+	}
+	for _, test := range tests {
+		e, err := ParseLicenseExpr(test.expr)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.expr, err)
+			continue
+		}
+		h, err := e.header()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.expr, err)
+			continue
+		}
+		if !strings.HasPrefix(h, "Copyright (c) <year> <owner>\n\n") || !strings.HasSuffix(h, test.expected) {
+			t.Errorf("%s: got %q; want %q", test.expr, h, test.expected)
+		}
+	}
+}
+
+func TestExpressionOps(t *testing.T) {
+	var err error
+	lapp := app
+	lapp.Path, err = ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(lapp.Path)
+	lapp.Owner = "Trillian"
+	lapp.Year = "1999"
+	lapp.Expression, err = ParseLicenseExpr("MIT OR Apache-2.0 OR GPL-2.0+ WITH Classpath-exception-2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops, err := lapp.expressionOps()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := []string{"LICENSE-MIT", "LICENSE-Apache-2.0", "LICENSE-GPL-2.0-or-later", "LICENSE-Classpath-exception-2.0"}
+	if len(ops) != len(want) {
+		t.Fatalf("got %d ops; want %d", len(ops), len(want))
+	}
+	for i, op := range ops {
+		if filepath.Base(op.Path) != want[i] {
+			t.Errorf("%d: got %s; want %s", i, filepath.Base(op.Path), want[i])
+		}
+	}
+	if !strings.Contains(string(ops[0].Data), "Copyright (c) 1999 Trillian") {
+		t.Errorf("LICENSE-MIT: the placeholders weren't filled:\n%s", ops[0].Data)
+	}
+
+	lapp.Expression, err = ParseLicenseExpr("MIT OR WTFPL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = lapp.expressionOps()
+	if err == nil || !strings.HasPrefix(err.Error(), "WTFPL: the license's text isn't in the license corpus") {
+		t.Errorf("got %v; want a text isn't in the license corpus error", err)
+	}
+}
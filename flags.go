@@ -0,0 +1,279 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Flag is the definition of a flag that the generated application will have.
+type Flag struct {
+	Name    string   `json:"name"`    // the name of the flag, as used on the command line.
+	Aliases []string `json:"aliases"` // other names for the flag, e.g. v for verbose.
+	Type    string   `json:"type"`    // the type of the flag; string is the default.
+	Default string   `json:"default"` // the default value.
+	Usage   string   `json:"usage"`   // the usage text.
+	Comment string   `json:"comment"` // the comment for the Config field; usage is used if empty.
+	Env     string   `json:"env"`     // the environment variable, without the app's prefix, that can set the flag.
+}
+
+// flagType is a type that the flag package has a XxxVar func for.
+type flagType struct {
+	goType string // the type of the Config field
+	fn     string // the flag func used to register the flag
+	// zero is the default value used when one isn't specified
+	zero string
+	// literal validates the default and returns it as a Go literal.
+	literal func(s string) (string, error)
+}
+
+// flagTypes are the supported types, by the name used in the project
+// definition. Any other type is assumed to be the name of a type that
+// implements flag.Value.
+var flagTypes = map[string]flagType{
+	"string":   {"string", "StringVar", `""`, func(s string) (string, error) { return strconv.Quote(s), nil }},
+	"bool":     {"bool", "BoolVar", "false", boolLiteral},
+	"int":      {"int", "IntVar", "0", intLiteral(strconv.IntSize)},
+	"int64":    {"int64", "Int64Var", "0", intLiteral(64)},
+	"uint":     {"uint", "UintVar", "0", uintLiteral(strconv.IntSize)},
+	"uint64":   {"uint64", "Uint64Var", "0", uintLiteral(64)},
+	"float64":  {"float64", "Float64Var", "0", floatLiteral},
+	"duration": {"time.Duration", "DurationVar", "0", durationLiteral},
+}
+
+// Field returns the name of the Config field for the flag. The field name is
+// the flag name in camel case, e.g. log-file becomes LogFile.
+func (f Flag) Field() string {
+	var field []rune
+	upper := true
+	for _, r := range f.Name {
+		if r == '-' || r == '_' || r == '.' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		field = append(field, r)
+	}
+	return string(field)
+}
+
+// Names returns the flag's name followed by its aliases.
+func (f Flag) Names() []string {
+	return append([]string{f.Name}, f.Aliases...)
+}
+
+// kind returns the flag's type; an empty type is a string.
+func (f Flag) kind() string {
+	if f.Type == "" {
+		return "string"
+	}
+	return f.Type
+}
+
+// isValue returns whether the flag's type is a custom flag.Value type.
+func (f Flag) isValue() bool {
+	_, ok := flagTypes[f.kind()]
+	return !ok
+}
+
+// GoType returns the type of the flag's Config field.
+func (f Flag) GoType() string {
+	t, ok := flagTypes[f.kind()]
+	if !ok {
+		return f.Type
+	}
+	return t.goType
+}
+
+// FieldComment returns the comment for the flag's Config field.
+func (f Flag) FieldComment() string {
+	if f.Comment != "" {
+		return f.Comment
+	}
+	return f.Usage
+}
+
+// validate checks that the type is usable and that the default is valid for
+// the type. The usage and comment must be a single line: the comment, or the
+// usage, is the Config field's line comment.
+func (f Flag) validate() error {
+	if strings.ContainsAny(f.Usage, "\r\n") {
+		return errors.New("usage: must be a single line")
+	}
+	if strings.ContainsAny(f.Comment, "\r\n") {
+		return errors.New("comment: must be a single line")
+	}
+	if f.Env != "" && !isEnvName(f.Env) {
+		return fmt.Errorf("env %q: not a valid environment variable name", f.Env)
+	}
+	t, ok := flagTypes[f.kind()]
+	if !ok {
+		if !isIdent(f.Type) {
+			return fmt.Errorf("unsupported type: %s", f.Type)
+		}
+		// The default of a flag.Value is whatever its zero value is.
+		if f.Default != "" {
+			return fmt.Errorf("%s: a default is not supported for flag.Value types", f.Type)
+		}
+		return nil
+	}
+	if f.Default == "" {
+		return nil
+	}
+	_, err := t.literal(f.Default)
+	if err != nil {
+		return fmt.Errorf("default %q: %s", f.Default, err)
+	}
+	return nil
+}
+
+// Register returns the statements that register the flag with set, e.g.
+// flag.StringVar(&cfg.Addr, "addr", ":8080", "listen address") for the set
+// flag and the Config var cfg. Each alias is registered using the same Config
+// field; the statements are separated by a newline.
+func (f Flag) Register(set, cfg string) (string, error) {
+	regs := make([]string, 0, len(f.Aliases)+1)
+	if f.isValue() {
+		for _, name := range f.Names() {
+			regs = append(regs, fmt.Sprintf("%s.Var(&%s.%s, %q, %q)", set, cfg, f.Field(), name, f.Usage))
+		}
+		return strings.Join(regs, "\n"), nil
+	}
+	t := flagTypes[f.kind()]
+	def := t.zero
+	if f.Default != "" {
+		var err error
+		def, err = t.literal(f.Default)
+		if err != nil {
+			return "", fmt.Errorf("flag %s: default %q: %s", f.Name, f.Default, err)
+		}
+	}
+	for _, name := range f.Names() {
+		regs = append(regs, fmt.Sprintf("%s.%s(&%s.%s, %q, %s, %q)", set, t.fn, cfg, f.Field(), name, def, f.Usage))
+	}
+	return strings.Join(regs, "\n"), nil
+}
+
+func boolLiteral(s string) (string, error) {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatBool(b), nil
+}
+
+func intLiteral(size int) func(string) (string, error) {
+	return func(s string) (string, error) {
+		_, err := strconv.ParseInt(s, 0, size)
+		if err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+}
+
+func uintLiteral(size int) func(string) (string, error) {
+	return func(s string) (string, error) {
+		_, err := strconv.ParseUint(s, 0, size)
+		if err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+}
+
+// floatLiteral returns the float; NaN and infinity aren't Go literals, so
+// they aren't allowed.
+func floatLiteral(s string) (string, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return "", err
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", errors.New("not a finite number")
+	}
+	return s, nil
+}
+
+// durationLiteral returns the duration using the largest time unit that it
+// is a multiple of, e.g. 90s is 90 * time.Second.
+func durationLiteral(s string) (string, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return "", err
+	}
+	units := []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	}
+	if d == 0 {
+		return "0", nil
+	}
+	for _, u := range units {
+		if d%u.d == 0 {
+			return fmt.Sprintf("%d * %s", d/u.d, u.name), nil
+		}
+	}
+	return strconv.FormatInt(int64(d), 10), nil
+}
+
+// envPrefix returns the prefix of the environment variables that set an
+// app's flags: the app name, upper-cased, with anything that isn't a letter or
+// a digit replaced by an underscore, followed by an underscore, e.g. my-app
+// becomes MY_APP_.
+func envPrefix(name string) string {
+	prefix := []rune(strings.ToUpper(name))
+	for i, r := range prefix {
+		if !isEnvRune(r) {
+			prefix[i] = '_'
+		}
+	}
+	return string(prefix) + "_"
+}
+
+// isEnvName returns whether s is usable as an environment variable name.
+func isEnvName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !isEnvRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isEnvRune(r rune) bool {
+	return r == '_' || ('A' <= r && r <= 'Z') || ('a' <= r && r <= 'z') || ('0' <= r && r <= '9')
+}
+
+// isIdent returns whether s is a valid Go identifier.
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r == '_' || unicode.IsLetter(r) {
+			continue
+		}
+		if i > 0 && unicode.IsDigit(r) {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package main
+
+import "testing"
+
+func TestFlagField(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"v", "V"},
+		{"verbose", "Verbose"},
+		{"log-level", "LogLevel"},
+		{"log_level", "LogLevel"},
+		{"http.addr", "HttpAddr"},
+		{"dryRun", "DryRun"},
+	}
+	for i, test := range tests {
+		f := Flag{Name: test.name}
+		if f.Field() != test.expected {
+			t.Errorf("%d: got %q want %q", i, f.Field(), test.expected)
+		}
+	}
+}
+
+func TestFlagRegister(t *testing.T) {
+	tests := []struct {
+		flag     Flag
+		expected string
+	}{
+		{Flag{Name: "addr", Default: ":8080", Usage: "listen address"}, `flag.StringVar(&cfg.Addr, "addr", ":8080", "listen address")`},
+		{Flag{Name: "addr", Type: "string"}, `flag.StringVar(&cfg.Addr, "addr", "", "")`},
+		{Flag{Name: "v", Type: "bool", Usage: "verbose output"}, `flag.BoolVar(&cfg.V, "v", false, "verbose output")`},
+		{Flag{Name: "v", Type: "bool", Default: "T"}, `flag.BoolVar(&cfg.V, "v", true, "")`},
+		{Flag{Name: "n", Type: "int", Default: "-42"}, `flag.IntVar(&cfg.N, "n", -42, "")`},
+		{Flag{Name: "n", Type: "int64"}, `flag.Int64Var(&cfg.N, "n", 0, "")`},
+		{Flag{Name: "n", Type: "uint", Default: "0x10"}, `flag.UintVar(&cfg.N, "n", 0x10, "")`},
+		{Flag{Name: "n", Type: "uint64", Default: "42"}, `flag.Uint64Var(&cfg.N, "n", 42, "")`},
+		{Flag{Name: "ratio", Type: "float64", Default: "0.5"}, `flag.Float64Var(&cfg.Ratio, "ratio", 0.5, "")`},
+		{Flag{Name: "timeout", Type: "duration", Default: "90s"}, `flag.DurationVar(&cfg.Timeout, "timeout", 90 * time.Second, "")`},
+		{Flag{Name: "timeout", Type: "duration", Default: "1h"}, `flag.DurationVar(&cfg.Timeout, "timeout", 1 * time.Hour, "")`},
+		{Flag{Name: "timeout", Type: "duration", Default: "1.5ms"}, `flag.DurationVar(&cfg.Timeout, "timeout", 1500 * time.Microsecond, "")`},
+		{Flag{Name: "timeout", Type: "duration"}, `flag.DurationVar(&cfg.Timeout, "timeout", 0, "")`},
+		{Flag{Name: "level", Type: "Level", Usage: "log level"}, `flag.Var(&cfg.Level, "level", "log level")`},
+		{Flag{Name: "verbose", Aliases: []string{"v"}, Type: "bool"}, "flag.BoolVar(&cfg.Verbose, \"verbose\", false, \"\")\nflag.BoolVar(&cfg.Verbose, \"v\", false, \"\")"},
+		{Flag{Name: "level", Aliases: []string{"l", "lvl"}, Type: "Level"}, "flag.Var(&cfg.Level, \"level\", \"\")\nflag.Var(&cfg.Level, \"l\", \"\")\nflag.Var(&cfg.Level, \"lvl\", \"\")"},
+	}
+	for i, test := range tests {
+		got, err := test.flag.Register("flag", "cfg")
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if got != test.expected {
+			t.Errorf("%d: got %q want %q", i, got, test.expected)
+		}
+	}
+
+	// a default that isn't a Go literal isn't registered
+	_, err := Flag{Name: "ratio", Type: "float64", Default: "+Inf"}.Register("flag", "cfg")
+	if err == nil || err.Error() != `flag ratio: default "+Inf": not a finite number` {
+		t.Errorf("got %v want a not a finite number error", err)
+	}
+}
+
+func TestEnvPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"foo", "FOO_"},
+		{"Foo", "FOO_"},
+		{"my-app", "MY_APP_"},
+		{"my.app2", "MY_APP2_"},
+	}
+	for i, test := range tests {
+		got := envPrefix(test.name)
+		if got != test.expected {
+			t.Errorf("%d: got %q want %q", i, got, test.expected)
+		}
+	}
+}
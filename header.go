@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The styles of the license header that quine puts at the top of each file
+// that it generates.
+const (
+	headerSLH  = "slh"  // the license's SLH, Standard License Header, if it has one
+	headerSPDX = "spdx" // the SPDX-License-Identifier and SPDX-FileCopyrightText tags
+	headerNone = "none" // no header
+)
+
+// headerStyles are the header styles, in the order that they are listed in
+// the -header flag's usage.
+var headerStyles = []string{headerSLH, headerSPDX, headerNone}
+
+// validHeaderStyle returns an error if s isn't a header style.
+func validHeaderStyle(s string) error {
+	for _, style := range headerStyles {
+		if s == style {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown header style: %s; use one of %s", s, strings.Join(headerStyles, ", "))
+}
+
+// header returns the license header, as a comment, that goes at the top of
+// each generated file, using the app's header style. An empty Header is the
+// SLH style.
+func (a *App) header() (string, error) {
+	switch a.Header {
+	case "", headerSLH:
+		return a.slh()
+	case headerSPDX:
+		return a.spdxHeader(), nil
+	case headerNone:
+		return "", nil
+	}
+	return "", validHeaderStyle(a.Header)
+}
+
+// spdxID returns the SPDX-License-Identifier of the app's license: the
+// license expression, if it has one, otherwise the license's ID. If no license
+// is specified, an empty string is returned.
+func (a *App) spdxID() string {
+	if a.Expression != nil {
+		return a.Expression.String()
+	}
+	if a.License == None {
+		return ""
+	}
+	return a.License.ID()
+}
+
+// spdxHeader returns the SPDX style header, as used by REUSE: the license's
+// SPDX-License-Identifier tag followed by an SPDX-FileCopyrightText tag with
+// the years and name of each copyright holder. The copyright tag is left out
+// if the owner is unknown; if no license is specified, there is no header.
+func (a *App) spdxHeader() string {
+	id := a.spdxID()
+	if id == "" {
+		return ""
+	}
+	s := "// SPDX-License-Identifier: " + id + "\n"
+	for _, h := range a.holders() {
+		s += "// SPDX-FileCopyrightText: " + h.String() + "\n"
+	}
+	return s + "\n"
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHeader(t *testing.T) {
+	var err error
+	lapp := app
+	lapp.Path, err = ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(lapp.Path)
+	lapp.Owner = "Trillian"
+	lapp.Year = "1999"
+	lapp.Commands = []Command{{Name: "serve"}}
+
+	tests := []struct {
+		header   string
+		license  string
+		owner    string
+		holders  []Holder
+		expected string
+	}{
+		{headerSPDX, "Apache-2.0", "Trillian", nil, "// SPDX-License-Identifier: Apache-2.0\n// SPDX-FileCopyrightText: 1999 Trillian\n\npackage main\n"},
+		{headerSPDX, "MIT OR Apache-2.0", "Trillian", nil, "// SPDX-License-Identifier: MIT OR Apache-2.0\n// SPDX-FileCopyrightText: 1999 Trillian\n\npackage main\n"},
+		{headerSPDX, "Apache-2.0", "Trillian", []Holder{{"Acme Corp", "2019-2020"}}, "// SPDX-License-Identifier: Apache-2.0\n// SPDX-FileCopyrightText: 1999 Trillian\n// SPDX-FileCopyrightText: 2019-2020 Acme Corp\n\npackage main\n"},
+		{headerSPDX, "GPL-3.0+", "", nil, "// SPDX-License-Identifier: GPL-3.0-or-later\n\npackage main\n"},
+		{headerSPDX, "", "Trillian", nil, "package main\n"},
+		{headerSLH, "Apache-2.0", "Trillian", nil, "// Copyright 1999 Trillian\n// Licensed under the Apache License, Version 2.0"},
+		{headerSLH, "Apache-2.0", "Trillian", []Holder{{"Acme Corp", ""}}, "// Copyright 1999 Trillian\n// Copyright 1999 Acme Corp\n// Licensed under the Apache License, Version 2.0"},
+		{headerNone, "Apache-2.0", "Trillian", nil, "package main\n"},
+	}
+	for _, test := range tests {
+		lapp.Header = test.header
+		lapp.Owner, lapp.Holders = test.owner, test.holders
+		lapp.License, lapp.Expression = None, nil
+		e, err := ParseLicenseExpr(test.license)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e != nil && e.IsLicense() {
+			lapp.License = e.License
+		} else {
+			lapp.Expression = e
+		}
+		// every generated file has the header
+		for _, f := range []struct {
+			name   string
+			render func() (fileOp, error)
+		}{
+			{mainFile, lapp.mainOp},
+			{lapp.Name + "_main.go", lapp.appFileOp},
+			{"serve_cmd.go", func() (fileOp, error) { return lapp.commandFileOp(lapp.Commands[0]) }},
+		} {
+			op, err := f.render()
+			if err != nil {
+				t.Errorf("%s %s: %s: unexpected error: %s", test.header, test.license, f.name, err)
+				continue
+			}
+			if !strings.HasPrefix(string(op.Data), test.expected) {
+				t.Errorf("%s %s: %s: got %q\nwant prefix %q", test.header, test.license, f.name, op.Data, test.expected)
+			}
+		}
+	}
+
+	lapp.Header = "fdas"
+	_, err = lapp.header()
+	if err == nil || err.Error() != "unknown header style: fdas; use one of slh, spdx, none" {
+		t.Errorf("got %v; want an unknown header style error", err)
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// The actions that the headers command takes for a file.
+const (
+	headerAdd    = "add"    // the file doesn't have a license header
+	headerUpdate = "update" // the file's license header is stale
+	headerOK     = "ok"     // the file's license header is up to date
+	headerSkip   = "skip"   // the file is left alone; see headerChange.Reason
+)
+
+// headerChange is what the headers command does with a Go file.
+type headerChange struct {
+	Path   string
+	Action string
+	Reason string // why the file is skipped
+	Data   []byte // the file with the header added or updated
+	Mode   os.FileMode
+}
+
+// generatedRe matches the comment that marks a Go file as generated; see
+// https://golang.org/s/generatedcode.
+var generatedRe = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+// yearRe matches a year, or a range of years, e.g. 2015-2019.
+var yearRe = regexp.MustCompile(`\b((?:19|20)\d\d)(?:\s*-\s*(?:19|20)\d\d)?\b`)
+
+// commentBlock is a comment at the top of a Go file, before its package
+// clause: either consecutive // lines or a /* */ comment.
+type commentBlock struct {
+	start, end int // the offsets of the block in the file; end is after its last newline
+	text       string
+}
+
+// leadingComments returns the comment blocks at the top of src, along with
+// the offset of the line that ends them, usually the package clause.
+func leadingComments(src []byte) ([]commentBlock, int) {
+	var blocks []commentBlock
+	line := false // whether the previous line was a // comment
+	i := 0
+	for i < len(src) {
+		next := bytes.IndexByte(src[i:], '\n')
+		if next < 0 {
+			next = len(src)
+		} else {
+			next += i + 1
+		}
+		s := strings.TrimSpace(string(src[i:next]))
+		switch {
+		case s == "":
+			line = false
+		case strings.HasPrefix(s, "//"):
+			if line {
+				b := &blocks[len(blocks)-1]
+				b.end = next
+				b.text = string(src[b.start:b.end])
+			} else {
+				blocks = append(blocks, commentBlock{i, next, string(src[i:next])})
+			}
+			line = true
+		case strings.HasPrefix(s, "/*"):
+			end := bytes.Index(src[i:], []byte("*/"))
+			if end < 0 {
+				return blocks, i
+			}
+			next = bytes.IndexByte(src[i+end:], '\n')
+			if next < 0 {
+				next = len(src)
+			} else {
+				next += i + end + 1
+			}
+			blocks = append(blocks, commentBlock{i, next, string(src[i:next])})
+			line = false
+		default:
+			return blocks, i
+		}
+		i = next
+	}
+	return blocks, i
+}
+
+// isLicenseHeader returns whether the comment is a license header: it
+// mentions a copyright or a license and it isn't a build constraint.
+func isLicenseHeader(s string) bool {
+	if strings.HasPrefix(s, "//go:build") || strings.HasPrefix(s, "// +build") {
+		return false
+	}
+	s = strings.ToLower(s)
+	return strings.Contains(s, "copyright") || strings.Contains(s, "license")
+}
+
+// isPackageDoc returns whether the comment is a package's doc comment: one
+// with a line that starts with "Package", e.g. Package main does things.
+func isPackageDoc(s string) bool {
+	for _, l := range strings.Split(s, "\n") {
+		l = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(l), "/*"))
+		if strings.HasPrefix(l, "Package ") {
+			return true
+		}
+	}
+	return false
+}
+
+// headerYears returns the years of the copyright in the header that replaces
+// the existing header, old: from the first year in old's copyright line for
+// the owner, if it's earlier, to the app's year; see yearRange. The other
+// holders' lines are ignored; their years are their own.
+func (a *App) headerYears(old string) string {
+	for _, l := range strings.Split(old, "\n") {
+		if !strings.Contains(strings.ToLower(l), "copyright") || !strings.Contains(l, a.Owner) {
+			continue
+		}
+		if m := yearRe.FindStringSubmatch(l); m != nil {
+			return yearRange(m[1], a.Year)
+		}
+		break
+	}
+	return a.Year
+}
+
+// holdsCopyright returns whether any of the app's copyright holders are in
+// the header, s.
+func (a *App) holdsCopyright(s string) bool {
+	for _, h := range a.holders() {
+		if strings.Contains(s, h.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshHeader returns what is to be done with the Go file, src: its license
+// header is added if it doesn't have one and is updated if it is stale.
+// Generated files are skipped, as are files whose copyright is held by
+// someone other than the app's copyright holders. The header is the first of
+// the leading comments that is a license header, e.g. it may follow a build
+// constraint. A comment that is directly followed by the package clause is a
+// license header, not the package's doc comment, unless it's a Package
+// sentence; the header that replaces it is followed by a blank line.
+func (a *App) refreshHeader(src []byte, headers map[string]string) (headerChange, error) {
+	blocks, pkg := leadingComments(src)
+	if generatedRe.Match(src[:pkg]) {
+		return headerChange{Action: headerSkip, Reason: "generated"}, nil
+	}
+	var old *commentBlock
+	for i := range blocks {
+		if !isLicenseHeader(blocks[i].text) || blocks[i].end == pkg && isPackageDoc(blocks[i].text) {
+			continue
+		}
+		old = &blocks[i]
+		break
+	}
+
+	year := a.Year
+	if old != nil {
+		if strings.Contains(strings.ToLower(old.text), "copyright") && !a.holdsCopyright(old.text) {
+			return headerChange{Action: headerSkip, Reason: "the copyright isn't held by " + a.placeholderValue(valueOwner)}, nil
+		}
+		year = a.headerYears(old.text)
+	}
+	h, ok := headers[year]
+	if !ok {
+		la := *a
+		la.Year = year
+		s, err := la.header()
+		if err != nil {
+			return headerChange{}, err
+		}
+		h = strings.TrimRight(s, "\n") + "\n"
+		headers[year] = h
+	}
+
+	if old == nil {
+		return headerChange{Action: headerAdd, Data: append([]byte(h+"\n"), src...)}, nil
+	}
+	sep := ""
+	if old.end == pkg { // keep the header from being the package's doc comment
+		sep = "\n"
+	}
+	if old.text == h && sep == "" {
+		return headerChange{Action: headerOK}, nil
+	}
+	b := append([]byte{}, src[:old.start]...)
+	b = append(b, h+sep...)
+	b = append(b, src[old.end:]...)
+	return headerChange{Action: headerUpdate, Data: b}, nil
+}
+
+// moduleRoot returns the root of the module that dir is in: the closest
+// directory, starting with dir, that has a go.mod. If there isn't one, dir is
+// returned.
+func moduleRoot(dir string) string {
+	for d := dir; ; {
+		_, err := os.Stat(filepath.Join(d, "go.mod"))
+		if err == nil {
+			return d
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
+
+// root returns the root of the module that the app is in; see moduleRoot.
+func (a *App) root() string {
+	dir := a.Path
+	if a.CmdDir { // the path was adjusted to the command directory
+		dir = filepath.Dir(filepath.Dir(dir))
+	}
+	return moduleRoot(dir)
+}
+
+// HeaderChanges walks the Go files in root, skipping vendor, testdata, and
+// hidden directories, and any other modules, and returns what is to be done
+// with each of them; nothing is written.
+func (a *App) HeaderChanges(root string) ([]headerChange, error) {
+	if len(a.holders()) == 0 {
+		return nil, errors.New("the copyright owner isn't set; use -owner or -holder")
+	}
+	h, err := a.header()
+	if err != nil {
+		return nil, err
+	}
+	if h == "" {
+		return nil, fmt.Errorf("there's no header to add: the header style is %s and the license is %s", a.Header, a.spdxID())
+	}
+
+	var changes []headerChange
+	headers := map[string]string{} // by year
+	err = filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			name := fi.Name()
+			if path == root {
+				return nil
+			}
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil { // another module
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".go" {
+			return nil
+		}
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		c, err := a.refreshHeader(src, headers)
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+		c.Path, c.Mode = path, fi.Mode()
+		changes = append(changes, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// headersMain runs the headers command: quine [flags] headers [dir]. The
+// license headers of the Go files in dir, or, if it isn't specified, the
+// module that the app's path is in, are added or updated using the license,
+// owner, year, and header style flags. With -dry-run, nothing is written; with
+// -diff, the diffs of the files that would change are printed instead.
+func headersMain(args []string) int {
+	if len(args) > 1 || len(args) == 1 && strings.HasPrefix(args[0], "-") {
+		log.Printf("headers: usage: %s [flags] headers [dir]; the flags go before the command", exe)
+		return 2
+	}
+	parseFlags()
+	root := app.root()
+	if len(args) > 0 {
+		root = args[0]
+	}
+
+	changes, err := app.HeaderChanges(root)
+	if err != nil {
+		log.Printf("headers: error: %s", err)
+		return 1
+	}
+
+	if app.Diff {
+		var ops []fileOp
+		for _, c := range changes {
+			if c.Data != nil {
+				ops = append(ops, fileOp{Path: c.Path, Action: actionOverwrite, Data: c.Data})
+			}
+		}
+		return diffOps(ops)
+	}
+
+	counts := map[string]int{}
+	for _, c := range changes {
+		counts[c.Action]++
+		switch c.Action {
+		case headerOK:
+			continue
+		case headerSkip:
+			fmt.Printf("%-9s %s: %s\n", c.Action, c.Path, c.Reason)
+			continue
+		}
+		fmt.Printf("%-9s %s\n", c.Action, c.Path)
+		if app.DryRun {
+			continue
+		}
+		err = ioutil.WriteFile(c.Path, c.Data, c.Mode)
+		if err != nil {
+			log.Printf("headers: %s: error: %s", c.Path, err)
+			return 1
+		}
+	}
+	fmt.Printf("%d files: %d added, %d updated, %d up to date, %d skipped\n", len(changes), counts[headerAdd], counts[headerUpdate], counts[headerOK], counts[headerSkip])
+	return 0
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRefreshHeader(t *testing.T) {
+	lapp := app
+	lapp.Owner = "Trillian"
+	lapp.Year = "1999"
+	lapp.License = MIT
+	lapp.Header = headerSPDX
+	h := "// SPDX-License-Identifier: MIT\n// SPDX-FileCopyrightText: 1999 Trillian\n"
+
+	tests := []struct {
+		src      string
+		action   string
+		expected string
+	}{
+		{"package main\n", headerAdd, h + "\npackage main\n"},
+		{"// Package main does things.\npackage main\n", headerAdd, h + "\n// Package main does things.\npackage main\n"},
+		{"//go:build linux\n\npackage main\n", headerAdd, h + "\n//go:build linux\n\npackage main\n"},
+		{h + "\npackage main\n", headerOK, ""},
+		{"// SPDX-License-Identifier: Apache-2.0\n// SPDX-FileCopyrightText: 1999 Trillian\n\n//go:build linux\n\npackage main\n", headerUpdate, h + "\n//go:build linux\n\npackage main\n"},
+		{"// Copyright 1995-1997 Trillian. All rights reserved.\n\npackage main\n", headerUpdate, "// SPDX-License-Identifier: MIT\n// SPDX-FileCopyrightText: 1995-1999 Trillian\n\npackage main\n"},
+		{"/* Copyright 1999 Trillian\n * Licensed under the MIT license.\n */\n\npackage main\n", headerUpdate, h + "\npackage main\n"},
+		{"// Copyright 1999 Zaphod\n\npackage main\n", headerSkip, ""},
+		{"//go:build linux\n\n// Copyright 1997 Trillian\n// Licensed under the MIT license.\n\npackage main\n", headerUpdate, "//go:build linux\n\n// SPDX-License-Identifier: MIT\n// SPDX-FileCopyrightText: 1997-1999 Trillian\n\npackage main\n"},
+		{"//go:build linux\n\n" + h + "\npackage main\n", headerOK, ""},
+		{"// Copyright 1997 Trillian\n// Licensed under the MIT license.\npackage main\n", headerUpdate, "// SPDX-License-Identifier: MIT\n// SPDX-FileCopyrightText: 1997-1999 Trillian\n\npackage main\n"},
+		{h + "package main\n", headerUpdate, h + "\npackage main\n"},
+		{"// Copyright 1999 Zaphod\npackage main\n", headerSkip, ""},
+		{"// Package main does things under the MIT license.\npackage main\n", headerAdd, h + "\n// Package main does things under the MIT license.\npackage main\n"},
+		{"// Package main does things.\n\n// Copyright 1997 Trillian\n\npackage main\n", headerUpdate, "// Package main does things.\n\n// SPDX-License-Identifier: MIT\n// SPDX-FileCopyrightText: 1997-1999 Trillian\n\npackage main\n"},
+		{"// Code generated by stringer. DO NOT EDIT.\n\npackage main\n", headerSkip, ""},
+	}
+	for i, test := range tests {
+		c, err := lapp.refreshHeader([]byte(test.src), map[string]string{})
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if c.Action != test.action {
+			t.Errorf("%d: action: got %s want %s", i, c.Action, test.action)
+			continue
+		}
+		if string(c.Data) != test.expected {
+			t.Errorf("%d: got %q\nwant %q", i, c.Data, test.expected)
+		}
+	}
+	// a file that is held by another of the copyright holders is updated
+	lapp.Holders = []Holder{{"Zaphod", "1998"}}
+	c, err := lapp.refreshHeader([]byte("// Copyright 1998 Zaphod\n\npackage main\n"), map[string]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := "// SPDX-License-Identifier: MIT\n// SPDX-FileCopyrightText: 1999 Trillian\n// SPDX-FileCopyrightText: 1998 Zaphod\n\npackage main\n"
+	if c.Action != headerUpdate || string(c.Data) != expected {
+		t.Errorf("holders: got %s %q\nwant %s %q", c.Action, c.Data, headerUpdate, expected)
+	}
+}
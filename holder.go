@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Holder is a copyright holder, other than the app's owner, along with the
+// year, or years, of their copyright, e.g. 2019-2026.
+type Holder struct {
+	Name string `json:"name"`
+	Year string `json:"year"` // the app's year, if it's empty
+}
+
+// holderRe matches a copyright holder as it's given to the -holder flag: the
+// years, if there are any, followed by the name.
+var holderRe = regexp.MustCompile(`^(?:(` + yearsPattern + `)\s+)?(.+)$`)
+
+// parseHolder parses s, a copyright holder's years, which are optional, and
+// name, e.g. 2019-2026 Acme Corp and contributors.
+func parseHolder(s string) (Holder, error) {
+	m := holderRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Holder{}, errors.New("copyright holder: no name")
+	}
+	return Holder{Name: m[2], Year: m[1]}, nil
+}
+
+// String returns the holder's years and name.
+func (h Holder) String() string {
+	return strings.TrimSpace(h.Year + " " + h.Name)
+}
+
+// holderList is the -holder flag's value: each use of the flag adds a holder.
+type holderList []Holder
+
+func (l *holderList) String() string {
+	var s []string
+	for _, h := range *l {
+		s = append(s, h.String())
+	}
+	return strings.Join(s, "; ")
+}
+
+func (l *holderList) Set(s string) error {
+	h, err := parseHolder(s)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, h)
+	return nil
+}
+
+// holders returns the app's copyright holders: the owner, with the app's
+// year, followed by the other holders. A holder without a year has the app's
+// year.
+func (a *App) holders() []Holder {
+	var hs []Holder
+	if a.Owner != "" {
+		hs = append(hs, Holder{a.Owner, a.Year})
+	}
+	for _, h := range a.Holders {
+		if h.Year == "" {
+			h.Year = a.Year
+		}
+		hs = append(hs, h)
+	}
+	return hs
+}
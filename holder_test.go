@@ -0,0 +1,60 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHolder(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected Holder
+		err      string
+	}{
+		{"Acme Corp", Holder{Name: "Acme Corp"}, ""},
+		{"2019 Acme Corp", Holder{"Acme Corp", "2019"}, ""},
+		{" 2019-2026 Acme Corp and contributors ", Holder{"Acme Corp and contributors", "2019-2026"}, ""},
+		{"2015, 2017-2019 Acme Corp", Holder{"Acme Corp", "2015, 2017-2019"}, ""},
+		{"1984 Inc.", Holder{"Inc.", "1984"}, ""},
+		{"", Holder{}, "copyright holder: no name"},
+	}
+	for _, test := range tests {
+		h, err := parseHolder(test.s)
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%q: got error %q want %q", test.s, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%q: got %+v want error %q", test.s, h, test.err)
+			continue
+		}
+		if h != test.expected {
+			t.Errorf("%q: got %+v want %+v", test.s, h, test.expected)
+		}
+	}
+}
+
+func TestHolders(t *testing.T) {
+	var l holderList
+	for _, s := range []string{"2019-2026 Acme Corp", "Trillian"} {
+		err := l.Set(s)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", s, err)
+		}
+	}
+	if l.String() != "2019-2026 Acme Corp; Trillian" {
+		t.Errorf("got %q want %q", l.String(), "2019-2026 Acme Corp; Trillian")
+	}
+
+	a := App{Owner: "Zaphod Beeblebrox", Year: "1942", Holders: l}
+	expected := []Holder{{"Zaphod Beeblebrox", "1942"}, {"Acme Corp", "2019-2026"}, {"Trillian", "1942"}}
+	if hs := a.holders(); !reflect.DeepEqual(hs, expected) {
+		t.Errorf("got %+v want %+v", hs, expected)
+	}
+	a.Owner = ""
+	if hs := a.holders(); !reflect.DeepEqual(hs, expected[1:]) {
+		t.Errorf("no owner: got %+v want %+v", hs, expected[1:])
+	}
+}
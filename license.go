@@ -1,27 +1,75 @@
 package main
 
-import (
-	"strconv"
-	"strings"
+// Constants for the licenses in quine's license corpus using their SPDX short
+// code. Any other license in the SPDX License List is also a License; see
+// LicenseFromString.
+const (
+	None       License = ""
+	BSD0Clause License = "0BSD"
+	Apache20   License = "Apache-2.0"
+	BSD2Clause License = "BSD-2-Clause"
+	BSD3Clause License = "BSD-3-Clause"
+	CC010      License = "CC0-1.0"
+	EPL20      License = "EPL-2.0"
+	GPL20      License = "GPL-2.0"
+	GPL30      License = "GPL-3.0"
+	ISC        License = "ISC"
+	LGPL20     License = "LGPL-2.0"
+	LGPL21     License = "LGPL-2.1"
+	LGPL30     License = "LGPL-3.0"
+	MIT        License = "MIT"
+	MPL20      License = "MPL-2.0"
+	Unlicense  License = "Unlicense"
+	AGPL30     License = "AGPL-3.0"
 )
 
-// Constants for OSI Approved License using their SPDX short code.
+// The -only and -or-later versions of the GNU licenses. The deprecated ID,
+// e.g. GPL-2.0, is the base license of its versions; the compatibility check
+// treats it as the -only license, see onlyVersions.
 const (
-	None License = iota
-	Apache20
-	BSD2Clause
-	BSD3Clause
-	GPL20
-	GPL30
-	LGPL20
-	LGPL21
-	LGPL30
-	MIT
-	MPL20
+	AGPL30Only    License = "AGPL-3.0-only"
+	AGPL30OrLater License = "AGPL-3.0-or-later"
+	GPL20Only     License = "GPL-2.0-only"
+	GPL20OrLater  License = "GPL-2.0-or-later"
+	GPL30Only     License = "GPL-3.0-only"
+	GPL30OrLater  License = "GPL-3.0-or-later"
+	LGPL20Only    License = "LGPL-2.0-only"
+	LGPL20OrLater License = "LGPL-2.0-or-later"
+	LGPL21Only    License = "LGPL-2.1-only"
+	LGPL21OrLater License = "LGPL-2.1-or-later"
+	LGPL30Only    License = "LGPL-3.0-only"
+	LGPL30OrLater License = "LGPL-3.0-or-later"
 )
 
-// License represents an OSI Approved License.
-type License int
+// corpusLicenses are the licenses whose files are in quine's license corpus.
+var corpusLicenses = []License{
+	BSD0Clause, AGPL30, AGPL30Only, AGPL30OrLater, Apache20, BSD2Clause, BSD3Clause, CC010, EPL20,
+	GPL20, GPL20Only, GPL20OrLater, GPL30, GPL30Only, GPL30OrLater, ISC,
+	LGPL20, LGPL20Only, LGPL20OrLater, LGPL21, LGPL21Only, LGPL21OrLater,
+	LGPL30, LGPL30Only, LGPL30OrLater, MIT, MPL20, Unlicense,
+}
+
+// licenseVersions are the licenses that are a version of another license:
+// they use its files, unless they have their own, e.g. an -or-later license's
+// SLH, and its placeholders and notice flags.
+var licenseVersions = map[License]License{
+	AGPL30Only:    AGPL30,
+	AGPL30OrLater: AGPL30,
+	GPL20Only:     GPL20,
+	GPL20OrLater:  GPL20,
+	GPL30Only:     GPL30,
+	GPL30OrLater:  GPL30,
+	LGPL20Only:    LGPL20,
+	LGPL20OrLater: LGPL20,
+	LGPL21Only:    LGPL21,
+	LGPL21OrLater: LGPL21,
+	LGPL30Only:    LGPL30,
+	LGPL30OrLater: LGPL30,
+}
+
+// License is a license in the SPDX License List; its value is the license's
+// SPDX Identifier.
+type License string
 
 // Returns the SPDX Identifier (short code).
 func (l License) String() string {
@@ -30,32 +78,19 @@ func (l License) String() string {
 
 // Returns the SPDX Identifier (short code).
 func (l License) ID() string {
-	switch l {
-	case Apache20:
-		return "Apache-2.0"
-	case BSD2Clause:
-		return "BSD-2-Clause"
-	case BSD3Clause:
-		return "BSD-3-Clause"
-	case GPL20:
-		return "GPL-2.0"
-	case GPL30:
-		return "GPL-3.0"
-	case LGPL20:
-		return "LGPL-2.0"
-	case LGPL21:
-		return "LGPL-2.1"
-	case LGPL30:
-		return "LGPL-3.0"
-	case MIT:
-		return "MIT"
-	case MPL20:
-		return "MPL-2.0"
-	case None:
+	if l == None {
 		return "None"
-	default:
-		return strconv.Itoa(int(l))
 	}
+	return string(l)
+}
+
+// base returns the license that l is a version of; l if it isn't a version of
+// another license.
+func (l License) base() License {
+	if b, ok := licenseVersions[l]; ok {
+		return b
+	}
+	return l
 }
 
 // UnsupportedLicenseErr occurs when a string cannot be matched with a quine
@@ -69,11 +104,12 @@ func (e UnsupportedLicenseErr) Error() string {
 }
 
 // LicenseFromString will return the license associated with a string. The
-// string may be the license's full name or identifier (short code) as listed
-// by SPDX,org. Variants of the short code that drop the punctuation and/or any
-// trailing 0 are also accepted. For full names, the license name should not
-// include any quotes: e,g, BSD 2-clause Simplified License not BSD 2-clause
-// "Simplified" License.
+// string may be the identifier (short code) or full name of any license in
+// the SPDX License List; see spdx.go. Variants of the short code that drop
+// the punctuation and/or any trailing 0 are also accepted. For full names,
+// the license name may omit its quoted words, any leading GNU, and any
+// trailing only: e,g, BSD 2-clause Simplified License or BSD 2-clause License
+// for BSD 2-clause "Simplified" License.
 //
 // An empty string will result in `None` and is not considered an error. Any
 // license text that can't be matched will result in an error.
@@ -83,39 +119,13 @@ func LicenseFromString(s string) (License, error) {
 	if s == "" { // nothing specified is not an error state.
 		return None, nil
 	}
-
-	v := strings.ToUpper(s)
-	switch v {
-	case "APACHE LICENSE 2.0", "APACHE LICENSE 2", "APACHE-2.0", "APACHE-2", "APACHE20", "APACHE2":
-		return Apache20, nil
-	case "BSD-2-CLAUSE LICENSE", "BSD-2-CLAUSE SIMPLIFIED LICENSE", "BSD-2-CLAUSE", "BSD2CLAUSE", "BSD-2", "BSD2":
-		return BSD2Clause, nil
-	case "BSD-3-CLAUSE LICENSE", "BSD-3-CLAUSE NEW OR REVISED LICENSE", "BSD-3-CLAUSE", "BSD3CLAUSE", "BSD-3", "BSD3":
-		return BSD3Clause, nil
-	case "GNU GENERAL PUBLIC LICENSE V2.0 ONLY", "GNU GENERAL PUBLIC LICENSE V2 ONLY", "GNU GENERAL PUBLIC LICENSE V2.0", "GNU GENERAL PUBLIC LICENSE V2",
-		"GENERAL PUBLIC LICENSE V2.0 ONLY", "GENERAL PUBLIC LICENSE V2.0", "GENERAL PUBLIC LICENSE V2 ONLY", "GENERAL PUBLIC LICENSE V2",
-		"GPL-2.0", "GPL-2", "GPL-20", "GPL20", "GPL2":
-		return GPL20, nil
-	case "GNU GENERAL PUBLIC LICENSE V3.0 ONLY", "GNU GENERAL PUBLIC LICENSE V3 ONLY", "GNU GENERAL PUBLIC LICENSE V3.0", "GNU GENERAL PUBLIC LICENSE V3",
-		"GENERAL PUBLIC LICENSE V3.0 ONLY", "GENERAL PUBLIC LICENSE V3.0", "GENERAL PUBLIC LICENSE V3 ONLY", "GENERAL PUBLIC LICENSE V3",
-		"GPL-3.0", "GPL-3", "GPL-30", "GPL30", "GPL3":
-		return GPL30, nil
-	case "GNU LESSER GENERAL PUBLIC LICENSE V2.0 ONLY", "GNU LESSER GENERAL PUBLIC LICENSE V2 ONLY", "GNU LESSER GENERAL PUBLIC LICENSE V2.0", "GNU LESSER GENERAL PUBLIC LICENSE V2",
-		"LESSER GENERAL PUBLIC LICENSE V2.0 ONLY", "LESSER GENERAL PUBLIC LICENSE V2 ONLY", "LESSER GENERAL PUBLIC LICENSE V2.0", "LESSER GENERAL PUBLIC LICENSE V2",
-		"LGPL-2.0", "LGPL-2", "LGPL20", "LGPL2":
-		return LGPL20, nil
-	case "GNU LESSER GENERAL PUBLIC LICENSE V2.1 ONLY", "GNU LESSER GENERAL PUBLIC LICENSE V2.1", "LESSER GENERAL PUBLIC LICENSE V2.1 ONLY", "LESSER GENERAL PUBLIC LICENSE V2.1",
-		"LGPL-2.1", "LGPL21":
-		return LGPL21, nil
-	case "GNU LESSER GENERAL PUBLIC LICENSE V3.0 ONLY", "GNU LESSER GENERAL PUBLIC LICENSE V3 ONLY", "GNU LESSER GENERAL PUBLIC LICENSE V3.0", "GNU LESSER GENERAL PUBLIC LICENSE V3",
-		"LESSER GENERAL PUBLIC LICENSE V3.0 ONLY", "LESSER GENERAL PUBLIC LICENSE V3 ONLY", "LESSER GENERAL PUBLIC LICENSE V3.0", "LESSER GENERAL PUBLIC LICENSE V3",
-		"LGPL-3.0", "LGPL-3", "LGPL30", "LGPL3":
-		return LGPL30, nil
-	case "MIT LICENSE", "MIT":
-		return MIT, nil
-	case "MOZILLA PUBLIC LICENSE 2.0", "MOZILLA PUBLIC LICENSE 2", "MPL-2.0", "MPL-2", "MPL20", "MPL2":
-		return MPL20, nil
-	default:
+	idx, err := spdxLicenses()
+	if err != nil {
+		return None, err
+	}
+	id, ok := idx.keys[matchKey(s)]
+	if !ok {
 		return None, UnsupportedLicenseErr{s}
 	}
+	return License(id), nil
 }
@@ -33,57 +33,57 @@ func TestLicenseFromString(t *testing.T) {
 		{"BSD-3", BSD3Clause, ""},
 		{"BSD3", BSD3Clause, ""},
 		{"bsd3", BSD3Clause, ""},
-		{"GNU GENERAL PUBLIC LICENSE V2.0 ONLY", GPL20, ""},
-		{"Gnu General Public License V2 Only", GPL20, ""},
-		{"Gnu General Public License V2.0", GPL20, ""},
-		{"Gnu General Public License V2", GPL20, ""},
-		{"General Public License V2.0", GPL20, ""},
-		{"General Public License V2", GPL20, ""},
+		{"GNU GENERAL PUBLIC LICENSE V2.0 ONLY", GPL20Only, ""},
+		{"Gnu General Public License V2 Only", GPL20Only, ""},
+		{"Gnu General Public License V2.0", GPL20Only, ""},
+		{"Gnu General Public License V2", GPL20Only, ""},
+		{"General Public License V2.0", GPL20Only, ""},
+		{"General Public License V2", GPL20Only, ""},
 		{"GPL-2.0", GPL20, ""},
 		{"GPL-20", GPL20, ""},
 		{"GPL-2", GPL20, ""},
 		{"GPL20", GPL20, ""},
 		{"GPL2", GPL20, ""},
-		{"GNU GENERAL PUBLIC LICENSE V3.0 ONLY", GPL30, ""},
-		{"Gnu General Public License V3 Only", GPL30, ""},
-		{"Gnu General Public License V3.0", GPL30, ""},
-		{"Gnu General Public License V3", GPL30, ""},
-		{"General Public License V3.0", GPL30, ""},
-		{"General Public License V3", GPL30, ""},
+		{"GNU GENERAL PUBLIC LICENSE V3.0 ONLY", GPL30Only, ""},
+		{"Gnu General Public License V3 Only", GPL30Only, ""},
+		{"Gnu General Public License V3.0", GPL30Only, ""},
+		{"Gnu General Public License V3", GPL30Only, ""},
+		{"General Public License V3.0", GPL30Only, ""},
+		{"General Public License V3", GPL30Only, ""},
 		{"GPL-3.0", GPL30, ""},
 		{"GPL-30", GPL30, ""},
 		{"GPL-3", GPL30, ""},
 		{"GPL30", GPL30, ""},
 		{"GPL3", GPL30, ""},
-		{"GNU LESSER GENERAL PUBLIC LICENSE V2.0 ONLY", LGPL20, ""},
-		{"gnu lesser general public license v2.0 only", LGPL20, ""},
-		{"GNU Lesser General Public License V2.0", LGPL20, ""},
-		{"GNU Lesser General Public License V2 Only", LGPL20, ""},
-		{"GNU Lesser General Public License V2", LGPL20, ""},
-		{"Lesser General Public License V2.0 Only", LGPL20, ""},
-		{"Lesser General Public License V2.0", LGPL20, ""},
-		{"Lesser General Public License V2 Only", LGPL20, ""},
-		{"Lesser General Public License V2", LGPL20, ""},
+		{"GNU LESSER GENERAL PUBLIC LICENSE V2.0 ONLY", LGPL20Only, ""},
+		{"gnu lesser general public license v2.0 only", LGPL20Only, ""},
+		{"GNU Lesser General Public License V2.0", LGPL20Only, ""},
+		{"GNU Lesser General Public License V2 Only", LGPL20Only, ""},
+		{"GNU Lesser General Public License V2", LGPL20Only, ""},
+		{"Lesser General Public License V2.0 Only", LGPL20Only, ""},
+		{"Lesser General Public License V2.0", LGPL20Only, ""},
+		{"Lesser General Public License V2 Only", LGPL20Only, ""},
+		{"Lesser General Public License V2", LGPL20Only, ""},
 		{"LGPL-2.0", LGPL20, ""},
 		{"LGPL-2", LGPL20, ""},
 		{"LGPL20", LGPL20, ""},
 		{"LGPL2", LGPL20, ""},
-		{"GNU LESSER GENERAL PUBLIC LICENSE V2.1 ONLY", LGPL21, ""},
-		{"gnu lesser general public license v2.1 only", LGPL21, ""},
-		{"GNU Lesser General Public License V2.1", LGPL21, ""},
-		{"Lesser General Public License V2.1 Only", LGPL21, ""},
-		{"Lesser General Public License V2.1", LGPL21, ""},
+		{"GNU LESSER GENERAL PUBLIC LICENSE V2.1 ONLY", LGPL21Only, ""},
+		{"gnu lesser general public license v2.1 only", LGPL21Only, ""},
+		{"GNU Lesser General Public License V2.1", LGPL21Only, ""},
+		{"Lesser General Public License V2.1 Only", LGPL21Only, ""},
+		{"Lesser General Public License V2.1", LGPL21Only, ""},
 		{"LGPL-2.1", LGPL21, ""},
 		{"LGPL21", LGPL21, ""},
-		{"GNU LESSER GENERAL PUBLIC LICENSE V3.0 ONLY", LGPL30, ""},
-		{"gnu lesser general public license v3.0 only", LGPL30, ""},
-		{"GNU Lesser General Public License V3.0", LGPL30, ""},
-		{"GNU Lesser General Public License V3 Only", LGPL30, ""},
-		{"GNU Lesser General Public License V3", LGPL30, ""},
-		{"Lesser General Public License V3.0 Only", LGPL30, ""},
-		{"Lesser General Public License V3.0", LGPL30, ""},
-		{"Lesser General Public License V3 Only", LGPL30, ""},
-		{"Lesser General Public License V3", LGPL30, ""},
+		{"GNU LESSER GENERAL PUBLIC LICENSE V3.0 ONLY", LGPL30Only, ""},
+		{"gnu lesser general public license v3.0 only", LGPL30Only, ""},
+		{"GNU Lesser General Public License V3.0", LGPL30Only, ""},
+		{"GNU Lesser General Public License V3 Only", LGPL30Only, ""},
+		{"GNU Lesser General Public License V3", LGPL30Only, ""},
+		{"Lesser General Public License V3.0 Only", LGPL30Only, ""},
+		{"Lesser General Public License V3.0", LGPL30Only, ""},
+		{"Lesser General Public License V3 Only", LGPL30Only, ""},
+		{"Lesser General Public License V3", LGPL30Only, ""},
 		{"LGPL-3.0", LGPL30, ""},
 		{"LGPL-3", LGPL30, ""},
 		{"LGPL30", LGPL30, ""},
@@ -96,6 +96,33 @@ func TestLicenseFromString(t *testing.T) {
 		{"MPL-2", MPL20, ""},
 		{"MPL20", MPL20, ""},
 		{"MPL2", MPL20, ""},
+		{"ISC", ISC, ""},
+		{"ISC License", ISC, ""},
+		{"0BSD", BSD0Clause, ""},
+		{"BSD Zero Clause License", BSD0Clause, ""},
+		{"Unlicense", Unlicense, ""},
+		{"The Unlicense", Unlicense, ""},
+		{"CC0-1.0", CC010, ""},
+		{"CC0-1", CC010, ""},
+		{"Creative Commons Zero v1.0 Universal", CC010, ""},
+		{"EPL-2.0", License("EPL-2.0"), ""},
+		{"Eclipse Public License 2.0", License("EPL-2.0"), ""},
+		{"AGPL-3.0-only", License("AGPL-3.0-only"), ""},
+		{"agpl-3.0-or-later", License("AGPL-3.0-or-later"), ""},
+		{"GNU Affero General Public License v3.0 or later", License("AGPL-3.0-or-later"), ""},
+		{"GPL-2.0+", License("GPL-2.0+"), ""},
+		{"GPL-2.0-only", GPL20Only, ""},
+		{"GPL-2.0-or-later", GPL20OrLater, ""},
+		{"GNU General Public License v2.0 or later", GPL20OrLater, ""},
+		{"gpl-3.0-only", GPL30Only, ""},
+		{"GPL-3.0-or-later", GPL30OrLater, ""},
+		{"General Public License v3 or later", GPL30OrLater, ""},
+		{"LGPL-2.0-or-later", LGPL20OrLater, ""},
+		{"GNU Library General Public License v2 or later", LGPL20OrLater, ""},
+		{"GNU Lesser General Public License v2.0 or later", LGPL20OrLater, ""},
+		{"LGPL-2.1-only", LGPL21Only, ""},
+		{"GNU Lesser General Public License v2.1 or later", LGPL21OrLater, ""},
+		{"LGPL-3.0-or-later", LGPL30OrLater, ""},
 		{"", None, ""},
 		{"fdas", None, "unsupported license: fdas"},
 		{"Gen Public License", None, "unsupported license: Gen Public License"},
@@ -135,6 +162,12 @@ func TestID(t *testing.T) {
 		{LGPL30, "LGPL-3.0"},
 		{MIT, "MIT"},
 		{MPL20, "MPL-2.0"},
+		{BSD0Clause, "0BSD"},
+		{CC010, "CC0-1.0"},
+		{ISC, "ISC"},
+		{Unlicense, "Unlicense"},
+		{GPL20OrLater, "GPL-2.0-or-later"},
+		{LGPL30Only, "LGPL-3.0-only"},
 	}
 	for _, test := range tests {
 		s := test.l.String()
@@ -143,3 +176,21 @@ func TestID(t *testing.T) {
 		}
 	}
 }
+
+func TestBase(t *testing.T) {
+	tests := []struct {
+		l        License
+		expected License
+	}{
+		{GPL20Only, GPL20},
+		{GPL30OrLater, GPL30},
+		{LGPL21OrLater, LGPL21},
+		{GPL30, GPL30},
+		{MIT, MIT},
+	}
+	for _, test := range tests {
+		if b := test.l.base(); b != test.expected {
+			t.Errorf("%s: got %s; want %s", test.l, b, test.expected)
+		}
+	}
+}
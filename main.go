@@ -18,10 +18,15 @@ const mainFile = "main.go"
 var (
 	exe        = filepath.Base(os.Args[0]) // name of executable
 	quinePath  string
-	licenseDir = "license"
+	licenseDir string // the directory of the license overrides; see licenseOverrideDir
 	license    string
+	cfgFile    string // the project definition file
+	// the directory of the template overrides; see templateOverrideDir
+	templateDir string
 
 	app App
+
+	currentYear = strconv.Itoa(time.Now().Year())
 )
 
 // App is the app that quine is to generate.
@@ -29,47 +34,111 @@ type App struct {
 	Name string
 	Path string
 	License
-	CmdDir  bool
-	buf     bytes.Buffer
-	wrapper linewrap.Wrap
-	Owner   string // the owner of the copyright.
-	Year    string // the year of t he copyright; current year
+	Expression  *LicenseExpr // the license expression, if the license isn't just a license; see ParseLicenseExpr
+	Header      string       // the style of the license header of each generated file; see header.go
+	CmdDir      bool
+	DryRun      bool // report what would be written instead of writing it
+	Show        bool // with DryRun, also report the rendered files
+	Diff        bool // diff the generated files with the files on disk instead of writing them
+	buf         bytes.Buffer
+	wrapper     linewrap.Wrap
+	Owner       string   // the owner of the copyright.
+	ownerSource string   // where the owner came from; see defaultOwner
+	Year        string   // the year of t he copyright; current year
+	Holders     []Holder // the other copyright holders; see holders
+	// extend the year of an existing copyright to a range ending with the
+	// current year when the generated files change; see planYears
+	YearRange    bool
+	existingYear bool   // the year is that of an existing copyright
+	Flags        []Flag // the flags that the app is to have; from the project definition
+	// the commands that the app is to have; from the project definition
+	Commands []Command
 }
 
 func init() {
-	// set app information
-	var err error
-	app.Owner, err = githubUsername()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s: error: %s\n", exe, err)
-		fmt.Fprintf(os.Stderr, "%s: the copyright owner information will not be set unless it is either provided via flag or config\n")
-	}
-	app.Year = strconv.Itoa(time.Now().Year())
+	// set app information; the owner is set by parseFlags, see defaultOwner
+	app.Year = currentYear
 	app.wrapper = linewrap.New()
 	app.wrapper.LineComment(true)
 
 	quinePath = os.Getenv("QUINEPATH")
+	flag.StringVar(&cfgFile, "cfg", "", "project definition file; if empty, reponame.json will be used, if it exists")
 	flag.StringVar(&app.Name, "app", "", "name of the application; only use if it is different than the name of the repo")
-	flag.StringVar(&license, "license", "", "name of license for the project; use the SPDX short identifier for the language: https://spdx.org/licenses/")
-	flag.StringVar(&licenseDir, "licensedir", licenseDir, "the directory that the licenses are in; this is joined with the quinepath or WD to make the full path to the license directory")
+	flag.StringVar(&license, "license", "", "name of license for the project; use the SPDX short identifier for the license, https://spdx.org/licenses/, or an SPDX license expression, e.g. \"MIT OR Apache-2.0\"; if it isn't set, it's detected from the app's LICENSE or COPYING file")
+	flag.StringVar(&app.Header, "header", headerSLH, "the style of the license header of each generated file: slh, the license's standard license header; spdx, the SPDX-License-Identifier and SPDX-FileCopyrightText tags; or none")
+	flag.StringVar(&licenseDir, "licensedir", "", "the directory of any license files that replace the built-in ones; this is joined with the quinepath or WD to make the full path to the directory; if empty, the license directory in the quinepath is used, if the quinepath is set")
+	flag.StringVar(&templateDir, "templatedir", "", "the directory of any templates that replace the built-in ones; if empty, the overrides directory in the quinepath is used, if the quinepath is set")
 	flag.StringVar(&app.Path, "path", "", "path of project repo, relative to $GOPATH/src; if empty the WD will be used")
-	flag.StringVar(&app.Owner, "owner", app.Owner, "name of the copyright owner")
-	flag.StringVar(&app.Year, "year", app.Year, "yyyy for copyright")
+	flag.StringVar(&app.Owner, "owner", "", "name of the copyright owner; if it isn't set, the owner of the copyright in the app's LICENSE or main.go, if there is one, is used; otherwise it's the user.name, or user.email, of the repo's git config or the global git config, $GIT_AUTHOR_NAME, or the organization that the module path is mapped to in the quinepath's "+ownersFile)
+	flag.Var((*holderList)(&app.Holders), "holder", "another copyright holder, with the years of their copyright if they differ from the year, e.g. \"2019-2026 Acme Corp and contributors\"; it can be used more than once")
+	flag.StringVar(&app.Year, "year", app.Year, "yyyy for copyright; if it isn't set, the year of the copyright in the app's LICENSE or main.go, if there is one, is used")
+	flag.BoolVar(&app.YearRange, "year-range", false, "when the year is that of the app's existing copyright and the generated files change, extend it to a range that ends with the current year, e.g. 2017-"+currentYear)
 	flag.BoolVar(&app.CmdDir, "cmd", false, "use a cmd directory for package main")
+	flag.BoolVar(&app.DryRun, "dry-run", false, "render everything but write nothing; each file is reported with what would be done with it: create, overwrite, or skip")
+	flag.BoolVar(&app.Diff, "diff", false, "render everything but write nothing; print a unified diff of main.go, and the LICENSE, if there is one, with the files on disk and exit with 1 if they differ")
+	flag.BoolVar(&app.Show, "show", false, "with -dry-run, also print the rendered content of each file that would be written")
 
 	log.SetFlags(0)
 	log.SetPrefix(exe + ": ")
 }
 
 func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() > 0 {
+		os.Exit(run(flag.Args()))
+	}
 	parseFlags()
-
-	// make the output dir, just in case it doesn't exist
-	err := os.MkdirAll(app.Path, 0764)
+	err := app.checkLicenseText()
 	if err != nil {
-		log.Fatalf("error: mkdirall: %s", err)
+		log.Print(err)
+		os.Exit(1)
 	}
 
 	// exit using whatever is returned as the return code
 	os.Exit(app.Generate())
 }
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] [app]\n", exe)
+	fmt.Fprintf(os.Stderr, "       %s [flags] command [args]\n", exe)
+	fmt.Fprint(os.Stderr, "\nThe app is generated in the path, or the WD; it's named app, if it's specified, like the -app flag.\n")
+	fmt.Fprint(os.Stderr, "\nCommands:\n")
+	fmt.Fprint(os.Stderr, "  headers [dir]         add or update the license header of the module's, or dir's, Go files\n")
+	fmt.Fprint(os.Stderr, "  licenses list         list the supported licenses and where their files come from\n")
+	fmt.Fprint(os.Stderr, "  licenses spdx         list the licenses in the SPDX license list and whether their text is in the corpus\n")
+	fmt.Fprint(os.Stderr, "  licenses verify       check that the license files have what every supported license needs\n")
+	fmt.Fprint(os.Stderr, "  licenses deps [dir]   write the licenses of the module's, or dir's, dependencies to third_party_licenses.json and THIRD_PARTY_NOTICES\n")
+	fmt.Fprint(os.Stderr, "  licenses check [dir]  check the licenses of the module's, or dir's, dependencies against the app's license\n")
+	fmt.Fprint(os.Stderr, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// run runs the quine command, args[0]. If args[0] isn't a command, and it's
+// the only argument, it's the name of the app to generate, as if it were set
+// by the -app flag.
+func run(args []string) int {
+	switch args[0] {
+	case "headers":
+		return headersMain(args[1:])
+	case "licenses":
+		return licensesMain(args[1:])
+	}
+	if len(args) > 1 {
+		log.Printf("unknown command: %s", args[0])
+		flag.Usage()
+		return 2
+	}
+	if app.Name != "" {
+		log.Printf("the app is named by both -app and %s; use one of them", args[0])
+		return 2
+	}
+	flag.Set("app", args[0]) // so it takes precedence over the project definition's name
+	parseFlags()
+	err := app.checkLicenseText()
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	return app.Generate()
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// matchThreshold is the lowest confidence at which a text is identified as a
+// license. A copy of a license is usually a match of 0.9 or more for its
+// text; similar licenses match each other about as well, e.g. BSD-3-Clause
+// and BSD-2-Clause, so the closest match is the one that is used.
+const matchThreshold = 0.85
+
+// placeholderRe matches the placeholders in the license corpus' texts, e.g.
+// <year> or [name of copyright owner].
+var placeholderRe = regexp.MustCompile(`<[a-z ]+>|\[[a-z ]+\]`)
+
+// licenseText is a license whose full text is in the license corpus.
+type licenseText struct {
+	License License
+	bigrams map[string]bool // the word pairs of the text; see bigrams
+}
+
+// licenseMatcher identifies a license, and any exceptions, by its text.
+type licenseMatcher struct {
+	texts      []licenseText
+	exceptions map[string]map[string]bool // the word pairs of each exception's text, by its ID
+}
+
+// newLicenseMatcher returns a matcher for the licenses in the SPDX License
+// List whose full text is in the license corpus, including any that were
+// added to the license override directory. The text of a -only or -or-later
+// license is its base license's, so a text is only matched to the base
+// license, e.g. GPL-3.0. The exceptions whose text is in the license corpus
+// are also matched.
+func newLicenseMatcher() (*licenseMatcher, error) {
+	idx, err := spdxLicenses()
+	if err != nil {
+		return nil, err
+	}
+	m := licenseMatcher{exceptions: map[string]map[string]bool{}}
+	for _, l := range idx.licenses {
+		b, _, err := readLicenseFile(strings.ToLower(l.ID))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("%s: %s", l.ID, err)
+		}
+		m.texts = append(m.texts, licenseText{License(l.ID), bigrams(b)})
+	}
+	for _, x := range idx.exceptions {
+		if m.exceptions[x.ID] != nil {
+			continue
+		}
+		b, _, err := readLicenseFile(strings.ToLower(x.ID))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("%s: %s", x.ID, err)
+		}
+		m.exceptions[x.ID] = bigrams(b)
+	}
+	return &m, nil
+}
+
+// Match returns the license whose text is the closest match for b along with
+// the confidence of the match, from 0 to 1: the Dice coefficient of their word
+// pairs. If the confidence is below matchThreshold, the license is None; the
+// confidence is still that of the closest match.
+func (m *licenseMatcher) Match(b []byte) (License, float64) {
+	l, conf := m.closest(b)
+	if conf < matchThreshold {
+		return None, conf
+	}
+	return l, conf
+}
+
+// closest returns the license whose text is the closest match for b, however
+// low the confidence of the match is.
+func (m *licenseMatcher) closest(b []byte) (License, float64) {
+	words := bigrams(b)
+	var best License
+	var conf float64
+	for _, t := range m.texts {
+		c := dice(words, t.bigrams)
+		if c > conf {
+			best, conf = t.License, c
+		}
+	}
+	return best, conf
+}
+
+// Exception returns the exception whose text is in b, e.g. a license's text
+// followed by the exception's, along with the confidence of the match: the
+// share of the exception's word pairs that are in b. If none of the
+// exceptions' confidence is at least matchThreshold, an empty string is
+// returned.
+func (m *licenseMatcher) Exception(b []byte) (string, float64) {
+	words := bigrams(b)
+	var best string
+	var conf float64
+	for id, x := range m.exceptions {
+		var n int
+		for s := range x {
+			if words[s] {
+				n++
+			}
+		}
+		c := float64(n) / float64(len(x))
+		if c > conf || c == conf && id < best {
+			best, conf = id, c
+		}
+	}
+	if conf < matchThreshold {
+		return "", conf
+	}
+	return best, conf
+}
+
+// bigrams returns the set of the pairs of consecutive words in the text.
+// Words are lower-cased and punctuation is ignored, so differences in
+// wrapping, quoting, and list markers don't matter. Copyright lines are left
+// out, as are the placeholders in a license's text: they differ from one copy
+// of a license to the next.
+func bigrams(b []byte) map[string]bool {
+	var words []string
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(line)), "copyright") {
+			continue
+		}
+		line = placeholderRe.ReplaceAllString(line, " ")
+		words = append(words, strings.FieldsFunc(strings.ToLower(line), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+	}
+	set := make(map[string]bool, len(words))
+	for i := 1; i < len(words); i++ {
+		set[words[i-1]+" "+words[i]] = true
+	}
+	return set
+}
+
+// dice returns the Dice coefficient of the two sets: twice the size of their
+// intersection over the sum of their sizes.
+func dice(a, b map[string]bool) float64 {
+	if len(a)+len(b) == 0 {
+		return 0
+	}
+	var n int
+	for s := range a {
+		if b[s] {
+			n++
+		}
+	}
+	return 2 * float64(n) / float64(len(a)+len(b))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLicenseMatcher(t *testing.T) {
+	m, err := newLicenseMatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := func(l License) string {
+		b, _, err := readLicenseFile(licenseFiles(l)[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	mit := text(MIT)
+	bsd3 := text(BSD3Clause)
+	tests := []struct {
+		name     string
+		text     string
+		expected License
+	}{
+		{"MIT", mit, MIT},
+		{"MIT with a copyright", strings.Replace(mit, "<year> <copyright holders>", "2016 Joel Scoble", 1), MIT},
+		{"MIT rewrapped", strings.Join(strings.Fields(strings.ToUpper(mit)), " "), MIT},
+		{"BSD-3-Clause", bsd3, BSD3Clause},
+		{"BSD-2-Clause", text(BSD2Clause), BSD2Clause},
+		{"Apache-2.0", text(Apache20), Apache20},
+		{"GPL-3.0", text(GPL30Only), GPL30},
+		{"ISC", text(ISC), ISC},
+		{"0BSD", text(BSD0Clause), BSD0Clause},
+		{"Unlicense", text(Unlicense), Unlicense},
+		{"MIT, then something else", mit + strings.Repeat("Some other terms apply to the files in the vendor directory.\n", 10), MIT},
+		{"not a license", "This is a README, not a license.\n", None},
+		{"empty", "", None},
+	}
+	for _, test := range tests {
+		l, conf := m.Match([]byte(test.text))
+		if l != test.expected {
+			t.Errorf("%s: got %q (%.3f) want %q", test.name, l, conf, test.expected)
+		}
+		if l != None && conf < matchThreshold {
+			t.Errorf("%s: got a confidence of %.3f, which is below the threshold", test.name, conf)
+		}
+	}
+}
+
+func TestLicenseMatcherException(t *testing.T) {
+	m, err := newLicenseMatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gpl, _, err := readLicenseFile("gpl-2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp, _, err := readLicenseFile("classpath-exception-2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := append(append(append([]byte{}, gpl...), "\n"...), cp...)
+	l, _ := m.Match(b)
+	if l != GPL20 {
+		t.Errorf("got %q want %q", l, GPL20)
+	}
+	x, _ := m.Exception(b)
+	if x != "Classpath-exception-2.0" {
+		t.Errorf("got %q want Classpath-exception-2.0", x)
+	}
+	x, _ = m.Exception(gpl)
+	if x != "" {
+		t.Errorf("gpl-2.0: got %q want no exception", x)
+	}
+}
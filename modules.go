@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// moduleRequire is a module that a go.mod requires.
+type moduleRequire struct {
+	Path     string
+	Version  string
+	Indirect bool // the requirement is marked // indirect
+}
+
+// readGoMod returns the module path and the requirements of the go.mod file.
+// Only the module and require directives are read; replace directives are
+// ignored.
+func readGoMod(file string) (string, []moduleRequire, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", nil, err
+	}
+	var mod string
+	var reqs []moduleRequire
+	block := false // in a require ( ... ) block
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		var comment string
+		if i := strings.Index(line, "//"); i >= 0 {
+			line, comment = line[:i], strings.TrimSpace(line[i+2:])
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch {
+		case block && fields[0] == ")":
+			block = false
+			continue
+		case block:
+		case fields[0] == "module" && len(fields) == 2:
+			mod = unquote(fields[1])
+			continue
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			block = true
+			continue
+		case fields[0] == "require":
+			fields = fields[1:]
+		default:
+			continue
+		}
+		if len(fields) != 2 {
+			return "", nil, fmt.Errorf("%s:%d: malformed requirement", file, n)
+		}
+		reqs = append(reqs, moduleRequire{Path: unquote(fields[0]), Version: unquote(fields[1]), Indirect: comment == "indirect"})
+	}
+	if err := s.Err(); err != nil {
+		return "", nil, fmt.Errorf("%s: %s", file, err)
+	}
+	return mod, reqs, nil
+}
+
+// unquote returns s without any double quotes or backquotes around it.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '`') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// moduleCacheDir returns the Go module cache: GOMODCACHE, if it is set,
+// otherwise pkg/mod in the first GOPATH directory, or in $HOME/go if the
+// GOPATH isn't set.
+func moduleCacheDir() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	gop := filepath.SplitList(os.Getenv("GOPATH"))
+	if len(gop) > 0 && gop[0] != "" {
+		return filepath.Join(gop[0], "pkg", "mod")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, "go", "pkg", "mod")
+}
+
+// escapeModulePath returns the module path, or version, as it is in the
+// module cache: each upper-case letter is replaced with a ! followed by the
+// lower-case letter.
+func escapeModulePath(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// moduleDir returns the directory of the module version in the module cache,
+// cache; false is returned if it isn't in the cache.
+func moduleDir(cache string, m moduleRequire) (string, bool) {
+	if cache == "" {
+		return "", false
+	}
+	dir := filepath.Join(cache, escapeModulePath(m.Path)+"@"+escapeModulePath(m.Version))
+	fi, err := os.Stat(dir)
+	if err != nil || !fi.IsDir() {
+		return "", false
+	}
+	return dir, true
+}
+
+// readGoSum returns the hashes of the module versions in the go.sum file,
+// keyed by the module path and version separated by a space. The hashes of
+// the modules' go.mod files are ignored.
+func readGoSum(file string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	sums := map[string]string{}
+	for n, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: malformed line", file, n+1)
+		}
+		if strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		sums[fields[0]+" "+fields[1]] = fields[2]
+	}
+	return sums, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadGoMod(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "go.mod")
+	err = ioutil.WriteFile(file, []byte(`module example.com/app // the app
+
+go 1.21
+
+require github.com/mohae/linewrap v0.1.0
+
+require (
+	github.com/BurntSushi/toml v1.3.2 // indirect
+	"golang.org/x/text" v0.14.0
+)
+
+replace github.com/mohae/linewrap => ../linewrap
+`), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod, reqs, err := readGoMod(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if mod != "example.com/app" {
+		t.Errorf("module: got %q want %q", mod, "example.com/app")
+	}
+	expected := []moduleRequire{
+		{"github.com/mohae/linewrap", "v0.1.0", false},
+		{"github.com/BurntSushi/toml", "v1.3.2", true},
+		{"golang.org/x/text", "v0.14.0", false},
+	}
+	if len(reqs) != len(expected) {
+		t.Fatalf("got %v want %v", reqs, expected)
+	}
+	for i, r := range reqs {
+		if r != expected[i] {
+			t.Errorf("%d: got %v want %v", i, r, expected[i])
+		}
+	}
+
+	err = ioutil.WriteFile(file, []byte("module example.com/app\n\nrequire (\n\tgithub.com/mohae/linewrap\n)\n"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = readGoMod(file)
+	if err == nil || err.Error() != file+":4: malformed requirement" {
+		t.Errorf("got %v want a malformed requirement error", err)
+	}
+}
+
+func TestEscapeModulePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"github.com/mohae/linewrap", "github.com/mohae/linewrap"},
+		{"github.com/BurntSushi/toml", "github.com/!burnt!sushi/toml"},
+		{"v1.0.0-RC1", "v1.0.0-!r!c1"},
+	}
+	for _, test := range tests {
+		if s := escapeModulePath(test.path); s != test.expected {
+			t.Errorf("got %q want %q", s, test.expected)
+		}
+	}
+}
+
+func TestReadGoSum(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "go.sum")
+	err = ioutil.WriteFile(file, []byte("github.com/mohae/linewrap v0.1.0 h1:abc=\ngithub.com/mohae/linewrap v0.1.0/go.mod h1:def=\n\ngolang.org/x/text v0.14.0/go.mod h1:ghi=\n"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sums, err := readGoSum(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(sums) != 1 || sums["github.com/mohae/linewrap v0.1.0"] != "h1:abc=" {
+		t.Errorf("got %v want the linewrap hash", sums)
+	}
+
+	err = ioutil.WriteFile(file, []byte("github.com/mohae/linewrap v0.1.0\n"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = readGoSum(file)
+	if err == nil || err.Error() != file+":1: malformed line" {
+		t.Errorf("got %v want a malformed line error", err)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// cliNotice is a license's CLI notice: the text that the app prints when it
+// starts and the flags that print the sections of the license that the text
+// refers to.
+type cliNotice struct {
+	Text  string
+	Flags []noticeSection
+}
+
+// noticeSection is a notice flag along with the text of its section of the
+// license; the generated app prints it, so it doesn't need the license file.
+type noticeSection struct {
+	noticeFlag
+	Text string
+}
+
+// noticeFlag is a flag that prints a section of the license: from the line
+// that starts with Start up to, but not including, the line that starts with
+// End. An empty End is the end of the license.
+type noticeFlag struct {
+	Name  string
+	Usage string
+	Start string
+	End   string
+}
+
+// Flag returns the definition of the flag.
+func (f noticeFlag) Flag() Flag {
+	return Flag{Name: f.Name, Type: "bool", Usage: f.Usage}
+}
+
+// noticeFlags are the flags of the licenses that have CLI notices, i.e. a
+// .cli file; the notices refer to them. The versions of a license have its
+// flags.
+var noticeFlags = map[License][]noticeFlag{
+	GPL20: {
+		{"show-w", "show the warranty details", "NO WARRANTY", "END OF TERMS AND CONDITIONS"},
+		{"show-c", "show the conditions for copying, distribution and modification", "TERMS AND CONDITIONS", "NO WARRANTY"},
+	},
+	GPL30: {
+		{"show-w", "show the warranty details", "15. Disclaimer of Warranty.", "END OF TERMS AND CONDITIONS"},
+		{"show-c", "show the conditions for conveying the program", "TERMS AND CONDITIONS", "15. Disclaimer of Warranty."},
+	},
+}
+
+// notice returns the license's CLI notice, with its placeholders replaced, if
+// it has one; otherwise nil is returned. Each of the notice's flags must
+// refer to a section that is in the license.
+func (a *App) notice() (*cliNotice, error) {
+	if a.License == None { // if no license is specified nothing to do
+		return nil, nil
+	}
+	files := licenseFiles(a.License)
+	b, _, err := readLicenseFile(files[2])
+	if err != nil {
+		if os.IsNotExist(err) { // not all licenses have CLI notices, this is not an error state
+			return nil, nil
+		}
+		return nil, fmt.Errorf("CLI notice: %s", err)
+	}
+	flags, ok := noticeFlags[a.License.base()]
+	if !ok {
+		return nil, fmt.Errorf("CLI notice: %s: the notice's flags are not defined", files[2])
+	}
+
+	text, _, err := readLicenseFile(files[0])
+	if err != nil {
+		return nil, fmt.Errorf("CLI notice: read license file: %s", err)
+	}
+	text, _ = a.fillPlaceholders(text)
+	sections := make([]noticeSection, len(flags))
+	for i, f := range flags {
+		start := lineIndex(string(text), f.Start)
+		if start < 0 {
+			return nil, fmt.Errorf("CLI notice: flag %s: %q is not in the license", f.Name, f.Start)
+		}
+		section := string(text[start:])
+		if f.End != "" {
+			end := lineIndex(section, f.End)
+			if end < 0 {
+				return nil, fmt.Errorf("CLI notice: flag %s: %q is not in the license", f.Name, f.End)
+			}
+			section = section[:end]
+		}
+		sections[i] = noticeSection{f, section}
+		for _, af := range a.Flags {
+			for _, n := range af.Names() {
+				if n == f.Name {
+					return nil, fmt.Errorf("CLI notice: flag %s: the flag is defined by the project", f.Name)
+				}
+			}
+		}
+	}
+
+	// if the value of any placeholder is unknown, it is not replaced.
+	b, _ = a.fillPlaceholders(b)
+	return &cliNotice{Text: string(b), Flags: sections}, nil
+}
+
+// flags returns the app's flags, other than logfile: those of the license's
+// CLI notice, if it has one, followed by the project's.
+func (a *App) flags() ([]Flag, error) {
+	n, err := a.notice()
+	if err != nil {
+		return nil, err
+	}
+	if n == nil {
+		return a.Flags, nil
+	}
+	var flags []Flag
+	for _, f := range n.Flags {
+		flags = append(flags, f.Flag())
+	}
+	return append(flags, a.Flags...), nil
+}
+
+// lineIndex returns the index of the first line of s that starts with
+// prefix, or -1 if there isn't one.
+func lineIndex(s, prefix string) int {
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], prefix) {
+			return i
+		}
+		j := strings.IndexByte(s[i:], '\n')
+		if j < 0 {
+			break
+		}
+		i += j + 1
+	}
+	return -1
+}
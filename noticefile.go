@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// noticeFileNames are the names of a module's NOTICE file, in the order that
+// they are looked for.
+var noticeFileNames = []string{"NOTICE", "NOTICE.txt", "NOTICE.md"}
+
+// usesApache returns whether the app's license is, or its license
+// expression has, the Apache License 2.0, which expects a NOTICE file.
+func (a *App) usesApache() bool {
+	if a.Expression == nil {
+		return a.License == Apache20
+	}
+	for _, l := range a.Expression.Licenses() {
+		if l == Apache20 {
+			return true
+		}
+	}
+	return false
+}
+
+// noticeOp returns the op of the app's NOTICE file; see noticeFile.
+func (a *App) noticeOp() (fileOp, error) {
+	b, err := a.noticeFile()
+	if err != nil {
+		return fileOp{}, err
+	}
+	return generatedOp(filepath.Join(a.Path, "NOTICE"), b)
+}
+
+// noticeFile returns the app's NOTICE file: the app's name and the copyright
+// of each of its holders, see App.holders, followed by the NOTICE files of
+// the modules that the app's go.mod requires that are in the module cache. If
+// the app isn't in a module, or it is but none of its dependencies have a
+// NOTICE file, the NOTICE only has the app's name and copyright. A NOTICE
+// needs a copyright owner: if the app doesn't have any holders, an error is
+// returned.
+func (a *App) noticeFile() ([]byte, error) {
+	hs := a.holders()
+	if len(hs) == 0 {
+		return nil, fmt.Errorf("the copyright owner isn't set; use -owner")
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n", a.Name)
+	for _, h := range hs {
+		fmt.Fprintf(&buf, "Copyright %s\n", h)
+	}
+
+	gomod := filepath.Join(moduleRoot(a.Path), "go.mod")
+	_, reqs, err := readGoMod(gomod)
+	if err != nil {
+		if os.IsNotExist(err) { // not a module; there are no dependencies
+			return buf.Bytes(), nil
+		}
+		return nil, fmt.Errorf("read go.mod: %s", err)
+	}
+	cache := moduleCacheDir()
+	var notices bytes.Buffer
+	for _, m := range reqs {
+		b, err := moduleNotice(cache, m)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			continue
+		}
+		title := m.Path + " " + m.Version
+		fmt.Fprintf(&notices, "\n%s\n%s\n%s\n", title, strings.Repeat("-", len(title)), bytes.TrimSpace(b))
+	}
+	if notices.Len() > 0 {
+		buf.WriteString("\nThis product includes software from the following modules; their NOTICE files follow.\n")
+		buf.Write(notices.Bytes())
+	}
+	return buf.Bytes(), nil
+}
+
+// moduleNotice returns the NOTICE file of the module version, m, if it is in
+// the module cache and has one; otherwise nil is returned.
+func moduleNotice(cache string, m moduleRequire) ([]byte, error) {
+	dir, ok := moduleDir(cache, m)
+	if !ok {
+		return nil, nil
+	}
+	for _, name := range noticeFileNames {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			return b, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s %s: %s", m.Path, m.Version, err)
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNoticeFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cache := filepath.Join(dir, "mod")
+	mc := os.Getenv("GOMODCACHE")
+	defer os.Setenv("GOMODCACHE", mc)
+	os.Setenv("GOMODCACHE", cache)
+
+	lapp := app
+	lapp.Name = "hoopy"
+	lapp.Owner = "Trillian"
+	lapp.Year = "1999"
+	lapp.License = Apache20
+	lapp.Path = filepath.Join(dir, "app")
+
+	// not a module
+	b, err := lapp.noticeFile()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(b) != "hoopy\nCopyright 1999 Trillian\n" {
+		t.Errorf("got %q", b)
+	}
+
+	// each holder has a copyright line
+	lapp.Holders = []Holder{{"Acme Corp", "2019-2020"}}
+	b, err = lapp.noticeFile()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(b) != "hoopy\nCopyright 1999 Trillian\nCopyright 2019-2020 Acme Corp\n" {
+		t.Errorf("holders: got %q", b)
+	}
+
+	// the holders are only from -holder
+	lapp.Owner = ""
+	b, err = lapp.noticeFile()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(b) != "hoopy\nCopyright 2019-2020 Acme Corp\n" {
+		t.Errorf("only holders: got %q", b)
+	}
+
+	// the owner is from the resolver chain; the git config is isolated so
+	// that $GIT_AUTHOR_NAME is the first source that has an owner.
+	lapp.Holders = nil
+	for _, env := range []string{"GIT_CONFIG_GLOBAL", "GIT_CONFIG_NOSYSTEM", "GIT_AUTHOR_NAME"} {
+		if v, ok := os.LookupEnv(env); ok {
+			defer os.Setenv(env, v)
+		} else {
+			defer os.Unsetenv(env)
+		}
+	}
+	os.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(dir, "gitconfig"))
+	os.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	os.Setenv("GIT_AUTHOR_NAME", "Ford Prefect")
+	lapp.ownerSource = ""
+	lapp.defaultOwner()
+	if lapp.Owner != "Ford Prefect" || lapp.ownerSource != ownerEnv {
+		t.Errorf("got %q from %q want %q from %q", lapp.Owner, lapp.ownerSource, "Ford Prefect", ownerEnv)
+	}
+	b, err = lapp.noticeFile()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(b) != "hoopy\nCopyright 1999 Ford Prefect\n" {
+		t.Errorf("discovered owner: got %q", b)
+	}
+
+	// no holders: there isn't a NOTICE without an owner
+	lapp.Owner = ""
+	_, err = lapp.noticeFile()
+	if err == nil || err.Error() != "the copyright owner isn't set; use -owner" {
+		t.Errorf("no holders: got %v want a copyright owner isn't set error", err)
+	}
+	lapp.Owner = "Trillian"
+	lapp.Path = filepath.Join(dir, "app")
+
+	files := map[string]string{
+		"app/go.mod": "module example.com/hoopy\n\nrequire (\n\tgithub.com/Zaphod/heart v1.0.0\n\texample.com/towel v0.2.0\n\texample.com/missing v0.1.0\n)\n",
+		"mod/github.com/!zaphod/heart@v1.0.0/NOTICE": "Heart of Gold\nCopyright 1978 Zaphod\n",
+		"mod/example.com/towel@v0.2.0/NOTICE.txt":    "\nTowel\n\n",
+		"mod/example.com/towel@v0.1.0/NOTICE":        "Towel, the old one\n",
+	}
+	for name, s := range files {
+		file := filepath.Join(dir, name)
+		err = os.MkdirAll(filepath.Dir(file), 0775)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(file, []byte(s), 0664)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err = lapp.noticeFile()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := `hoopy
+Copyright 1999 Trillian
+
+This product includes software from the following modules; their NOTICE files follow.
+
+github.com/Zaphod/heart v1.0.0
+------------------------------
+Heart of Gold
+Copyright 1978 Zaphod
+
+example.com/towel v0.2.0
+------------------------
+Towel
+`
+	if string(b) != expected {
+		t.Errorf("got %q\nwant %q", b, expected)
+	}
+
+	lapp.License = MIT
+	if lapp.usesApache() {
+		t.Error("MIT: got uses Apache")
+	}
+	lapp.Expression, err = ParseLicenseExpr("MIT OR Apache-2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lapp.usesApache() {
+		t.Error("MIT OR Apache-2.0: got doesn't use Apache")
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// The sources of the copyright owner, in order of precedence; the first one
+// that has an owner is used. See App.defaultOwner.
+const (
+	ownerFlag      = "the -owner flag"
+	ownerProject   = "the project definition"
+	ownerCopyright = "the existing copyright"
+	ownerGitLocal  = "the repo's git config"
+	ownerGitGlobal = "the global git config"
+	ownerEnv       = "GIT_AUTHOR_NAME"
+	ownerOrg       = "the organization of the module path"
+)
+
+// ownersFile is the file in the quinepath that maps module paths to the
+// organizations that own them, e.g. {"github.com/acme": "Acme Corp"}.
+const ownersFile = "owners.json"
+
+// gitConfig returns the value of the git config's key, e.g. user.name: the
+// global config's or, if dir isn't empty, that of the repo in dir. If git
+// isn't installed, or the key isn't set, an empty string is returned.
+func gitConfig(dir, key string) (string, error) {
+	args := []string{"config", "--global", key}
+	if dir != "" {
+		args = []string{"-C", dir, "config", "--local", key}
+	}
+	cmd := exec.Command("git", args...)
+	var buf, stderr bytes.Buffer
+	cmd.Stdout = &buf
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		if e, ok := err.(*exec.ExitError); ok && e.ExitCode() == 1 { // the key isn't set
+			return "", nil
+		}
+		if e, ok := err.(*exec.Error); ok && e.Err == exec.ErrNotFound {
+			return "", nil
+		}
+		if s := strings.TrimSpace(stderr.String()); s != "" {
+			err = fmt.Errorf("%s: %s", err, s)
+		}
+		return "", fmt.Errorf("git config %s: %s", key, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// gitRepo returns the root of the git repo that dir, which may not exist yet,
+// is in; an empty string if it isn't in one.
+func gitRepo(dir string) string {
+	for d := dir; ; {
+		_, err := os.Stat(filepath.Join(d, ".git"))
+		if err == nil {
+			return d
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return ""
+		}
+		d = parent
+	}
+}
+
+// modulePath returns the app's module path: the module of its go.mod or, if it
+// isn't in a module, its path relative to the GOPATH's src directory. If it's
+// neither, an empty string is returned.
+func (a *App) modulePath() string {
+	root := a.root()
+	mod, _, err := readGoMod(filepath.Join(root, "go.mod"))
+	if err == nil && mod != "" {
+		rel, err := filepath.Rel(root, a.Path)
+		if err != nil || rel == "." {
+			return mod
+		}
+		return mod + "/" + filepath.ToSlash(rel)
+	}
+	gop := os.Getenv("GOPATH")
+	if gop == "" {
+		gop = filepath.Join(os.Getenv("HOME"), "go")
+	}
+	rel, err := filepath.Rel(filepath.Join(gop, "src"), a.Path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// orgOwner returns the organization that owns the module path, mod, according
+// to the owners file in the quinepath, see ownersFile: the owner of the
+// longest path in the file that is mod or one of its parents. If there isn't
+// an owners file, or it doesn't have the path, an empty string is returned.
+func orgOwner(mod string) (string, error) {
+	if quinePath == "" || mod == "" {
+		return "", nil
+	}
+	file := filepath.Join(quinePath, ownersFile)
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	var owners map[string]string
+	err = json.Unmarshal(b, &owners)
+	if err != nil {
+		return "", fmt.Errorf("%s: %s", file, err)
+	}
+	var path, owner string
+	for p, o := range owners {
+		p = strings.TrimSuffix(p, "/")
+		if (mod == p || strings.HasPrefix(mod, p+"/")) && len(p) > len(path) {
+			path, owner = p, o
+		}
+	}
+	return owner, nil
+}
+
+// discoverOwner returns the copyright owner of an app that doesn't have one
+// from the flags, the project definition, or an existing copyright, along with
+// its source: the user.name of the git config of the app's repo, then that of
+// the global git config, then $GIT_AUTHOR_NAME, then the user.email of the
+// repo's and of the global git config, and then the organization of the app's
+// module path; see orgOwner. A name always wins over an email. A source that
+// fails is logged and skipped. If none of them have an owner, an empty string
+// is returned.
+func (a *App) discoverOwner() (owner, source string) {
+	local := func(key string) func() (string, error) {
+		return func() (string, error) {
+			repo := gitRepo(a.Path)
+			if repo == "" {
+				return "", nil
+			}
+			return gitConfig(repo, key)
+		}
+	}
+	global := func(key string) func() (string, error) {
+		return func() (string, error) { return gitConfig("", key) }
+	}
+	sources := []struct {
+		name  string
+		owner func() (string, error)
+	}{
+		{ownerGitLocal, local("user.name")},
+		{ownerGitGlobal, global("user.name")},
+		{ownerEnv, func() (string, error) { return os.Getenv("GIT_AUTHOR_NAME"), nil }},
+		{ownerGitLocal, local("user.email")},
+		{ownerGitGlobal, global("user.email")},
+		{ownerOrg, func() (string, error) { return orgOwner(a.modulePath()) }},
+	}
+	for _, src := range sources {
+		s, err := src.owner()
+		if err != nil {
+			log.Printf("warning: copyright owner: %s: %s", src.name, err)
+			continue
+		}
+		if s = strings.TrimSpace(s); s != "" {
+			return s, src.name
+		}
+	}
+	return "", ""
+}
+
+// defaultOwner sets the app's copyright owner, if it isn't set, to the one
+// that discoverOwner finds. Unless it's from the -owner flag, the owner and
+// where it came from are logged.
+func (a *App) defaultOwner() {
+	if a.Owner == "" {
+		a.Owner, a.ownerSource = a.discoverOwner()
+	}
+	if a.Owner == "" || a.ownerSource == ownerFlag {
+		return
+	}
+	log.Printf("the copyright owner is %s, from %s; use -owner to override it", a.Owner, a.ownerSource)
+}
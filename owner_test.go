@@ -0,0 +1,131 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestOrgOwner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	qp := quinePath
+	defer func() { quinePath = qp }()
+	quinePath = dir
+
+	// no owners file
+	owner, err := orgOwner("github.com/acme/hoopy")
+	if err != nil || owner != "" {
+		t.Errorf("no owners file: got %q, %v want an empty string", owner, err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, ownersFile), []byte(`{"github.com/acme": "Acme Corp", "github.com/acme/labs/": "Acme Labs", "github.com/ac": "Ac"}`), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		mod      string
+		expected string
+	}{
+		{"github.com/acme", "Acme Corp"},
+		{"github.com/acme/hoopy", "Acme Corp"},
+		{"github.com/acme/labs/frood/v2", "Acme Labs"},
+		{"github.com/acmeish/hoopy", ""},
+		{"example.com/hoopy", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		owner, err := orgOwner(test.mod)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.mod, err)
+			continue
+		}
+		if owner != test.expected {
+			t.Errorf("%s: got %q want %q", test.mod, owner, test.expected)
+		}
+	}
+}
+
+func TestDiscoverOwner(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+	dir, err := ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, env := range []string{"GIT_CONFIG_GLOBAL", "GIT_CONFIG_NOSYSTEM", "GIT_AUTHOR_NAME"} {
+		if v, ok := os.LookupEnv(env); ok {
+			defer os.Setenv(env, v)
+		} else {
+			defer os.Unsetenv(env)
+		}
+	}
+	global := filepath.Join(dir, "gitconfig")
+	os.Setenv("GIT_CONFIG_GLOBAL", global)
+	os.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	os.Setenv("GIT_AUTHOR_NAME", "")
+	qp := quinePath
+	defer func() { quinePath = qp }()
+	quinePath = dir
+
+	repo := filepath.Join(dir, "hoopy")
+	err = exec.Command("git", "init", "-q", repo).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(repo, "go.mod"), []byte("module github.com/acme/hoopy\n"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) {
+		err := exec.Command("git", args...).Run()
+		if err != nil {
+			t.Fatalf("git %v: %s", args, err)
+		}
+	}
+	lapp := app
+	lapp.Path = filepath.Join(repo, "cmd", "hoopy") // it doesn't exist yet
+
+	// each source, from the last to the first
+	tests := []struct {
+		set    func()
+		owner  string
+		source string
+	}{
+		{func() {}, "", ""},
+		{func() {
+			ioutil.WriteFile(filepath.Join(dir, ownersFile), []byte(`{"github.com/acme": "Acme Corp"}`), 0664)
+		}, "Acme Corp", ownerOrg},
+		{func() { git("config", "--global", "user.email", "trillian@example.com") }, "trillian@example.com", ownerGitGlobal},
+		{func() { git("-C", repo, "config", "--local", "user.email", "zaphod@example.com") }, "zaphod@example.com", ownerGitLocal},
+		{func() { os.Setenv("GIT_AUTHOR_NAME", "Ford Prefect") }, "Ford Prefect", ownerEnv},
+		{func() { git("config", "--global", "user.name", "Trillian") }, "Trillian", ownerGitGlobal}, // a name wins over the repo's email
+		{func() { git("-C", repo, "config", "--local", "user.name", "Zaphod Beeblebrox") }, "Zaphod Beeblebrox", ownerGitLocal},
+	}
+	for i, test := range tests {
+		test.set()
+		owner, source := lapp.discoverOwner()
+		if owner != test.owner || source != test.source {
+			t.Errorf("%d: got %q from %q want %q from %q", i, owner, source, test.owner, test.source)
+		}
+	}
+
+	// an owner that is set isn't replaced
+	lapp.Owner, lapp.ownerSource = "Arthur Dent", ownerFlag
+	lapp.defaultOwner()
+	if lapp.Owner != "Arthur Dent" || lapp.ownerSource != ownerFlag {
+		t.Errorf("got %q from %q want %q from %q", lapp.Owner, lapp.ownerSource, "Arthur Dent", ownerFlag)
+	}
+	lapp.Owner, lapp.ownerSource = "", ""
+	lapp.defaultOwner()
+	if lapp.Owner != "Zaphod Beeblebrox" || lapp.ownerSource != ownerGitLocal {
+		t.Errorf("got %q from %q want %q from %q", lapp.Owner, lapp.ownerSource, "Zaphod Beeblebrox", ownerGitLocal)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// The app values that placeholders are replaced with.
+const (
+	valueYear    = "year"    // the year of the copyright
+	valueOwner   = "owner"   // the copyright owner
+	valueProgram = "program" // the name of the app
+)
+
+// placeholder is a token in a license's files that is replaced with one of
+// the app's values.
+type placeholder struct {
+	Token string // the token, as it appears in the license's files
+	Value string // the app value that replaces it
+}
+
+// licensePlaceholders are the placeholders in each license's files: the
+// full text, the SLH, and the CLI notice. Every occurrence of a token is
+// replaced, wherever it is in the file. Every license has an entry, even if
+// it has no placeholders, other than the versions of another license, which
+// use its entry; see verifyCorpus.
+var licensePlaceholders = map[License][]placeholder{
+	BSD0Clause: {{"<year>", valueYear}, {"<copyright holders>", valueOwner}},
+	AGPL30:     {{"<year>", valueYear}, {"<name of author>", valueOwner}},
+	Apache20:   {{"[yyyy]", valueYear}, {"[name of copyright owner]", valueOwner}},
+	BSD2Clause: {{"<year>", valueYear}, {"<owner>", valueOwner}},
+	BSD3Clause: {{"<year>", valueYear}, {"<owner>", valueOwner}},
+	CC010:      nil, // a public domain dedication
+	EPL20:      nil, // the EPL doesn't have a notice with the copyright
+	GPL20:      {{"<year>", valueYear}, {"<name of author>", valueOwner}, {"<program>", valueProgram}},
+	GPL30:      {{"<year>", valueYear}, {"<name of author>", valueOwner}, {"<program>", valueProgram}},
+	ISC:        {{"<year>", valueYear}, {"<copyright holders>", valueOwner}},
+	LGPL20:     {{"<year>", valueYear}, {"<name of author>", valueOwner}},
+	LGPL21:     {{"<year>", valueYear}, {"<name of author>", valueOwner}},
+	LGPL30:     {{"<year>", valueYear}, {"<name of author>", valueOwner}},
+	MIT:        {{"<year>", valueYear}, {"<copyright holders>", valueOwner}},
+	MPL20:      nil, // the MPL's notice doesn't include the copyright
+	Unlicense:  nil, // a public domain dedication
+}
+
+// defaultPlaceholders are the placeholders of the licenses that aren't in
+// licensePlaceholders, e.g. the SPDX License List's licenses whose files are
+// in the license override directory: the tokens that the corpus uses.
+var defaultPlaceholders = []placeholder{
+	{"<year>", valueYear},
+	{"<owner>", valueOwner},
+	{"<copyright holders>", valueOwner},
+	{"<name of author>", valueOwner},
+	{"<program>", valueProgram},
+}
+
+// placeholders returns the license's placeholders; a version of another
+// license has that license's placeholders.
+func placeholders(l License) []placeholder {
+	ps, ok := licensePlaceholders[l.base()]
+	if !ok {
+		return defaultPlaceholders
+	}
+	return ps
+}
+
+// placeholderValue returns the app's value, v; an empty string means that it
+// is unknown.
+func (a *App) placeholderValue(v string) string {
+	switch v {
+	case valueYear:
+		return a.Year
+	case valueOwner:
+		var names []string
+		for _, h := range a.holders() {
+			names = append(names, h.Name)
+		}
+		if len(names) == 0 {
+			return ""
+		}
+		return listJoin(names, "and")
+	case valueProgram:
+		return a.Name
+	}
+	return ""
+}
+
+// fillPlaceholders replaces the license's placeholders in b, which is one of
+// the license's files, with the app's values. A copyright line, one with both
+// the year and owner placeholders, is repeated for each of the app's copyright
+// holders; see fillHolders. If the value of a placeholder is unknown, it is
+// not replaced; the placeholders in b that weren't replaced are returned,
+// sorted.
+func (a *App) fillPlaceholders(b []byte) ([]byte, []string) {
+	ps := placeholders(a.License)
+	b = a.fillHolders(b, ps)
+	var unfilled []string
+	for _, p := range ps {
+		tok := []byte(p.Token)
+		if !bytes.Contains(b, tok) {
+			continue
+		}
+		v := a.placeholderValue(p.Value)
+		if v == "" {
+			unfilled = append(unfilled, p.Token)
+			continue
+		}
+		b = bytes.Replace(b, tok, []byte(v), -1)
+	}
+	sort.Strings(unfilled)
+	return b, unfilled
+}
+
+// fillHolders replaces each copyright line in b, a line that has both a year
+// and an owner placeholder, with a line for each of the app's copyright
+// holders, with their year and name filled in, e.g. a BSD license's copyright
+// line becomes a block of them. If the app has only one holder, b is
+// returned: fillPlaceholders fills in the line.
+func (a *App) fillHolders(b []byte, ps []placeholder) []byte {
+	hs := a.holders()
+	if len(hs) < 2 {
+		return b
+	}
+	var buf bytes.Buffer
+	for _, line := range strings.SplitAfter(string(b), "\n") {
+		var years, owners []string // the line's placeholders
+		for _, p := range ps {
+			if !strings.Contains(line, p.Token) {
+				continue
+			}
+			switch p.Value {
+			case valueYear:
+				years = append(years, p.Token)
+			case valueOwner:
+				owners = append(owners, p.Token)
+			}
+		}
+		if len(years) == 0 || len(owners) == 0 {
+			buf.WriteString(line)
+			continue
+		}
+		text := strings.TrimSuffix(line, "\n")
+		for i, h := range hs {
+			l := text
+			for _, tok := range years {
+				l = strings.Replace(l, tok, h.Year, -1)
+			}
+			for _, tok := range owners {
+				l = strings.Replace(l, tok, h.Name, -1)
+			}
+			buf.WriteString(l)
+			if i < len(hs)-1 || strings.HasSuffix(line, "\n") {
+				buf.WriteByte('\n')
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+// warnUnfilled logs a warning for each of the license's files that has
+// placeholders that won't be filled because the app's value is unknown, e.g.
+// the owner wasn't set. Only the full text of a license expression's licenses
+// is used.
+func (a *App) warnUnfilled() error {
+	licenses := []License{a.License}
+	if a.Expression != nil {
+		licenses = a.Expression.Licenses()
+	}
+	la := *a
+	for _, l := range licenses {
+		if l == None { // if no license is specified nothing to do
+			continue
+		}
+		la.License = l
+		names := licenseFiles(l)
+		if a.Expression != nil {
+			names = names[:1]
+		}
+		for _, name := range names {
+			b, _, err := readLicenseFile(name)
+			if err != nil {
+				if os.IsNotExist(err) { // not all licenses have SLH or CLI files
+					continue
+				}
+				return fmt.Errorf("read license file: %s", err)
+			}
+			_, unfilled := la.fillPlaceholders(b)
+			if len(unfilled) > 0 {
+				log.Printf("warning: %s: the placeholders weren't filled: %s", name, strings.Join(unfilled, ", "))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFillPlaceholders(t *testing.T) {
+	// only test the line with the copyright and the placeholders that weren't
+	// filled; the line is the first line unless line is set
+	tests := []struct {
+		license  License
+		file     string
+		line     int
+		owner    string
+		year     string
+		expected string
+		unfilled []string
+	}{
+		{Apache20, "apache-2.0.slh", 0, "", "", "Copyright [yyyy] [name of copyright owner]", []string{"[name of copyright owner]", "[yyyy]"}},
+		{Apache20, "apache-2.0.slh", 0, "Zaphod Beeblebrox", "", "Copyright [yyyy] Zaphod Beeblebrox", []string{"[yyyy]"}},
+		{Apache20, "apache-2.0.slh", 0, "", "1942", "Copyright 1942 [name of copyright owner]", []string{"[name of copyright owner]"}},
+		{Apache20, "apache-2.0.slh", 0, "Zaphod Beeblebrox", "1942", "Copyright 1942 Zaphod Beeblebrox", nil},
+		{Apache20, "apache-2.0", 0, "", "", "Apache License", nil},
+		{BSD2Clause, "bsd-2-clause", 0, "", "", "Copyright (c) <year> <owner> All rights reserved.", []string{"<owner>", "<year>"}},
+		{BSD2Clause, "bsd-2-clause", 0, "Zaphod Beeblebrox", "1942", "Copyright (c) 1942 Zaphod Beeblebrox All rights reserved.", nil},
+		{BSD3Clause, "bsd-3-clause", 0, "", "1942", "Copyright (c) 1942 <owner>. All rights reserved.", []string{"<owner>"}},
+		{BSD3Clause, "bsd-3-clause", 0, "Zaphod Beeblebrox", "1942", "Copyright (c) 1942 Zaphod Beeblebrox. All rights reserved.", nil},
+		{GPL20, "gpl-2.0.slh", 0, "", "", "Copyright (C) <year> <name of author>", []string{"<name of author>", "<year>"}},
+		{GPL20, "gpl-2.0.slh", 0, "Zaphod Beeblebrox", "1942", "Copyright (C) 1942 Zaphod Beeblebrox", nil},
+		{GPL20, "gpl-2.0.cli", 0, "", "1942", "test  Copyright (C) 1942  <name of author>", []string{"<name of author>"}},
+		{GPL30, "gpl-3.0.slh", 0, "Zaphod Beeblebrox", "", "Copyright (C) <year> Zaphod Beeblebrox", []string{"<year>"}},
+		{GPL30, "gpl-3.0.cli", 0, "Zaphod Beeblebrox", "1942", "test  Copyright (C) 1942  Zaphod Beeblebrox", nil},
+		{LGPL20, "lgpl-2.0.slh", 0, "Zaphod Beeblebrox", "1942", "Copyright (C) 1942 Zaphod Beeblebrox", nil},
+		{LGPL21, "lgpl-2.1.slh", 0, "", "1942", "Copyright (C) 1942 <name of author>", []string{"<name of author>"}},
+		{LGPL30, "lgpl-3.0.slh", 0, "Zaphod Beeblebrox", "1942", "Copyright (C) 1942 Zaphod Beeblebrox", nil},
+		{MIT, "mit", 1, "", "", "Copyright (c) <year> <copyright holders>", []string{"<copyright holders>", "<year>"}},
+		{MIT, "mit", 1, "Zaphod Beeblebrox", "1942", "Copyright (c) 1942 Zaphod Beeblebrox", nil},
+		{MPL20, "mpl-2.0.slh", 0, "", "", "This Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0. If a copy of the MPL was not distributed with this file, You can obtain one at http://mozilla.org/MPL/2.0/.", nil},
+	}
+	a := app
+	for i, test := range tests {
+		b, _, err := readLicenseFile(test.file)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		a.License = test.license
+		a.Owner = test.owner
+		a.Year = test.year
+		v, unfilled := a.fillPlaceholders(b)
+		line := strings.Split(string(v), "\n")[test.line]
+		if line != test.expected {
+			t.Errorf("%d: got %q want %q", i, line, test.expected)
+		}
+		if !reflect.DeepEqual(unfilled, test.unfilled) {
+			t.Errorf("%d: unfilled: got %q want %q", i, unfilled, test.unfilled)
+		}
+	}
+
+	// every occurrence is replaced
+	a.License = GPL20
+	a.Owner = "Zaphod Beeblebrox"
+	v, _ := a.fillPlaceholders([]byte("<program> <program>\n<program>"))
+	if string(v) != "test test\ntest" {
+		t.Errorf("got %q want %q", v, "test test\ntest")
+	}
+}
+
+func TestFillHolders(t *testing.T) {
+	tests := []struct {
+		license  License
+		file     string
+		line     int
+		expected string
+	}{
+		{MIT, "mit", 1, "Copyright (c) 1942 Zaphod Beeblebrox\nCopyright (c) 2019-2020 Acme Corp\nCopyright (c) 1942 Trillian\n\nPermission"},
+		{BSD3Clause, "bsd-3-clause", 0, "Copyright (c) 1942 Zaphod Beeblebrox. All rights reserved.\nCopyright (c) 2019-2020 Acme Corp. All rights reserved.\nCopyright (c) 1942 Trillian. All rights reserved.\nRedistribution"},
+		{Apache20, "apache-2.0.slh", 0, "Copyright 1942 Zaphod Beeblebrox\nCopyright 2019-2020 Acme Corp\nCopyright 1942 Trillian\nLicensed under"},
+	}
+	a := app
+	a.Owner, a.Year = "Zaphod Beeblebrox", "1942"
+	a.Holders = []Holder{{"Acme Corp", "2019-2020"}, {"Trillian", ""}}
+	for _, test := range tests {
+		b, _, err := readLicenseFile(test.file)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.file, err)
+			continue
+		}
+		a.License = test.license
+		v, unfilled := a.fillPlaceholders(b)
+		lines := strings.SplitAfterN(string(v), "\n", test.line+1)
+		if !strings.HasPrefix(lines[test.line], test.expected) {
+			t.Errorf("%s: got %q want prefix %q", test.file, lines[test.line], test.expected)
+		}
+		if unfilled != nil {
+			t.Errorf("%s: unfilled: got %q want none", test.file, unfilled)
+		}
+	}
+
+	// a placeholder that isn't on a copyright line has all of the names
+	a.License = BSD3Clause
+	v, _ := a.fillPlaceholders([]byte("<year> <owner>\nthe name of <owner>"))
+	expected := "1942 Zaphod Beeblebrox\n2019-2020 Acme Corp\n1942 Trillian\nthe name of Zaphod Beeblebrox, Acme Corp, and Trillian"
+	if string(v) != expected {
+		t.Errorf("got %q want %q", v, expected)
+	}
+}
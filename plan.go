@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// The actions that Generate takes for a file.
+const (
+	actionCreate    = "create"
+	actionOverwrite = "overwrite"
+	actionSkip      = "skip" // the file is user-owned and already exists
+)
+
+// fileOp is what Generate is to do with one of the files that it generates.
+type fileOp struct {
+	Path   string
+	Action string
+	Data   []byte // the rendered file; nil if the file is skipped
+	User   bool   // the file is user-owned: it's only written if it doesn't exist
+}
+
+// generatedOp returns the op for a file that quine owns, b: the file is
+// always written, overwriting any existing file.
+func generatedOp(path string, b []byte) (fileOp, error) {
+	_, err := os.Stat(path)
+	if err == nil {
+		return fileOp{Path: path, Action: actionOverwrite, Data: b}, nil
+	}
+	if !os.IsNotExist(err) { // if the error wasn't IsNotExist, return the err.
+		return fileOp{}, fmt.Errorf("%s: %s", path, err)
+	}
+	return fileOp{Path: path, Action: actionCreate, Data: b}, nil
+}
+
+// userOp returns the op for a file that is user-owned: the file is only
+// written if it doesn't exist, to prevent overwriting any user code. The file
+// is only rendered if it is to be written.
+func userOp(path string, render func() ([]byte, error)) (fileOp, error) {
+	_, err := os.Stat(path)
+	if err == nil {
+		return fileOp{Path: path, Action: actionSkip, User: true}, nil
+	}
+	if !os.IsNotExist(err) { // if the error wasn't IsNotExist, return the err.
+		return fileOp{}, fmt.Errorf("%s: %s", path, err)
+	}
+	b, err := render()
+	if err != nil {
+		return fileOp{}, err
+	}
+	return fileOp{Path: path, Action: actionCreate, Data: b, User: true}, nil
+}
+
+// Plan renders the app's files and returns what Generate is to do with each
+// of them; nothing is written.
+func (a *App) Plan() ([]fileOp, error) {
+	var ops []fileOp
+	// If a license was specified, copy it to the path; an expression's
+	// licenses are each copied to their own file.
+	switch {
+	case a.Expression != nil:
+		lops, err := a.expressionOps()
+		if err != nil {
+			return nil, fmt.Errorf("copy %s: %s", a.Expression, err)
+		}
+		ops = append(ops, lops...)
+	case a.License != None:
+		op, err := a.licenseOp()
+		if err != nil {
+			return nil, fmt.Errorf("copy %s: %s", a.License, err)
+		}
+		ops = append(ops, op)
+	}
+	// The Apache License 2.0 expects a NOTICE file.
+	if a.usesApache() {
+		op, err := a.noticeOp()
+		if err != nil {
+			return nil, fmt.Errorf("NOTICE: %s", err)
+		}
+		ops = append(ops, op)
+	}
+
+	op, err := a.mainOp()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", mainFile, err)
+	}
+	ops = append(ops, op)
+
+	op, err = a.appFileOp()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", a.Name+"_main.go", err)
+	}
+	ops = append(ops, op)
+
+	for _, c := range a.Commands {
+		op, err = a.commandFileOp(c)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", c.File(), err)
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// write writes the op's file, unless it is skipped.
+func (op fileOp) write() error {
+	if op.Action == actionSkip {
+		return nil
+	}
+	// make the output dir, just in case it doesn't exist
+	err := os.MkdirAll(filepath.Dir(op.Path), 0764)
+	if err != nil {
+		return fmt.Errorf("mkdirall: %s", err)
+	}
+
+	// open the file and write
+	f, err := os.OpenFile(op.Path, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0664)
+	if err != nil {
+		return fmt.Errorf("open failed: %s", err)
+	}
+	defer f.Close()
+
+	n, err := f.Write(op.Data)
+	if err != nil {
+		return fmt.Errorf("write failed: %s", err)
+	}
+
+	fmt.Printf("%s: %d bytes were written to %s\n", exe, n, op.Path)
+	return nil
+}
+
+// report writes the op's action and path to w. If content is true, the
+// rendered file follows; skipped files have no content.
+func (op fileOp) report(w io.Writer, content bool) {
+	if op.Action == actionSkip {
+		fmt.Fprintf(w, "%-9s %s: user-owned and it already exists\n", op.Action, op.Path)
+		return
+	}
+	fmt.Fprintf(w, "%-9s %s\n", op.Action, op.Path)
+	if !content {
+		return
+	}
+	w.Write(op.Data)
+	if len(op.Data) > 0 && op.Data[len(op.Data)-1] != '\n' {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	var err error
+	lapp := app
+	lapp.Path, err = ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(lapp.Path)
+	lapp.License = MIT
+	lapp.Commands = []Command{{Name: "serve"}}
+
+	actions := func() []string {
+		ops, err := lapp.Plan()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		var s []string
+		for _, op := range ops {
+			s = append(s, filepath.Base(op.Path)+" "+op.Action)
+		}
+		return s
+	}
+	check := func(got, want []string) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("got %q want %q", got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%d: got %q want %q", i, got[i], want[i])
+			}
+		}
+	}
+
+	check(actions(), []string{"LICENSE create", "main.go create", lapp.Name + "_main.go create", "serve_cmd.go create"})
+
+	// a dry run writes nothing
+	lapp.DryRun = true
+	if code := lapp.Generate(); code != 0 {
+		t.Fatalf("dry run: got %d want 0", code)
+	}
+	fis, err := ioutil.ReadDir(lapp.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fis) != 0 {
+		t.Errorf("dry run: got %d files want 0", len(fis))
+	}
+
+	lapp.DryRun = false
+	if code := lapp.Generate(); code != 0 {
+		t.Fatalf("generate: got %d want 0", code)
+	}
+	check(actions(), []string{"LICENSE overwrite", "main.go overwrite", lapp.Name + "_main.go skip", "serve_cmd.go skip"})
+
+	var buf bytes.Buffer
+	fileOp{Path: "main.go", Action: actionCreate, Data: []byte("package main")}.report(&buf, true)
+	fileOp{Path: "app_main.go", Action: actionSkip}.report(&buf, true)
+	want := "create    main.go\npackage main\n\nskip      app_main.go: user-owned and it already exists\n"
+	if buf.String() != want {
+		t.Errorf("report: got %q want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// projectExt is the extension of project definition files.
+const projectExt = ".json"
+
+// Project is a project definition. It contains the information about the
+// application that quine is to generate and the flags that the application
+// is to have.
+type Project struct {
+	Name  string `json:"name"`
+	Owner string `json:"owner"`
+	Year  string `json:"year"`
+	// Holders are the copyright holders other than the owner.
+	Holders []Holder `json:"holders"`
+	License string   `json:"license"`
+	Header  string   `json:"header"` // the style of the license header; see header.go
+	// YearRange extends the year of an existing copyright to a range; see
+	// App.YearRange.
+	YearRange bool   `json:"yearRange"`
+	Flags     []Flag `json:"flags"`
+	// Commands are the app's commands; if there are any, the generated main
+	// func runs the command named by the first argument.
+	Commands []Command `json:"commands"`
+}
+
+// LoadProject reads the project definition file.
+func LoadProject(file string) (*Project, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read project definition: %s", err)
+	}
+	var p Project
+	err = json.Unmarshal(b, &p)
+	if err != nil {
+		return nil, fmt.Errorf("decode project definition %s: %s", file, err)
+	}
+	err = p.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+	return &p, nil
+}
+
+// findProjectFile returns the name of the project definition file for repo
+// in dir: reponame.json. If the file doesn't exist an empty string is
+// returned; this is not an error state.
+func findProjectFile(dir, repo string) (string, error) {
+	file := filepath.Join(dir, repo+projectExt)
+	_, err := os.Stat(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return file, nil
+}
+
+// Validate checks that the flag and command definitions are usable.
+func (p *Project) Validate() error {
+	// logfile is always generated
+	err := validateFlags(p.Flags, map[string]bool{"logfile": true}, map[string]bool{"LogFile": true})
+	if err != nil {
+		return err
+	}
+	cmds := map[string]bool{}
+	idents := map[string]string{} // the command that each identifier is from
+	for i, c := range p.Commands {
+		if c.Name == "" {
+			return fmt.Errorf("command %d: no name", i)
+		}
+		if cmds[c.Name] {
+			return fmt.Errorf("command %s: defined more than once", c.Name)
+		}
+		cmds[c.Name] = true
+		err = c.validate()
+		if err != nil {
+			return fmt.Errorf("command %s: %s", c.Name, err)
+		}
+		if name, ok := idents[c.ident()]; ok {
+			return fmt.Errorf("command %s: identifier %s is used by command %s", c.Name, c.ident(), name)
+		}
+		idents[c.ident()] = c.Name
+	}
+	return nil
+}
+
+// validateFlags checks that the flags are usable. names and fields are the
+// flag names and Config fields that are already in use; either may be nil.
+func validateFlags(flags []Flag, names, fields map[string]bool) error {
+	if names == nil {
+		names = map[string]bool{}
+	}
+	if fields == nil {
+		fields = map[string]bool{}
+	}
+	envs := map[string]bool{}
+	for i, f := range flags {
+		if f.Name == "" {
+			return fmt.Errorf("flag %d: no name", i)
+		}
+		for _, name := range f.Names() {
+			if name == "" {
+				return fmt.Errorf("flag %s: empty alias", f.Name)
+			}
+			if strings.HasPrefix(name, "-") {
+				return fmt.Errorf("flag %s: the name must not include the leading '-'", name)
+			}
+			if names[name] {
+				return fmt.Errorf("flag %s: defined more than once", name)
+			}
+			names[name] = true
+		}
+		field := f.Field()
+		if !isIdent(field) {
+			return fmt.Errorf("flag %s: %q is not a valid field name", f.Name, field)
+		}
+		if fields[field] {
+			return fmt.Errorf("flag %s: field %s is used by another flag", f.Name, field)
+		}
+		fields[field] = true
+		if f.Env != "" {
+			if envs[f.Env] {
+				return fmt.Errorf("flag %s: env %s is used by another flag", f.Name, f.Env)
+			}
+			envs[f.Env] = true
+		}
+		err := f.validate()
+		if err != nil {
+			return fmt.Errorf("flag %s: %s", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// Apply sets the App's information using the project definition. Anything
+// that was explicitly set using a flag, set, is left as is.
+func (p *Project) Apply(a *App, set map[string]bool) {
+	if p.Name != "" && !set["app"] {
+		a.Name = p.Name
+	}
+	if p.Owner != "" && !set["owner"] {
+		a.Owner = p.Owner
+		a.ownerSource = ownerProject
+	}
+	if p.Year != "" && !set["year"] {
+		a.Year = p.Year
+	}
+	if len(p.Holders) > 0 && !set["holder"] {
+		a.Holders = p.Holders
+	}
+	if p.License != "" && !set["license"] {
+		license = p.License
+	}
+	if p.Header != "" && !set["header"] {
+		a.Header = p.Header
+	}
+	if p.YearRange && !set["year-range"] {
+		a.YearRange = true
+	}
+	a.Flags = p.Flags
+	a.Commands = p.Commands
+}
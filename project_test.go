@@ -0,0 +1,147 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProjectValidate(t *testing.T) {
+	tests := []struct {
+		flags    []Flag
+		commands []Command
+		err      string
+	}{
+		{nil, nil, ""},
+		{[]Flag{{Name: "addr"}, {Name: "name", Type: "string"}}, nil, ""},
+		{[]Flag{{Name: ""}}, nil, "flag 0: no name"},
+		{[]Flag{{Name: "-addr"}}, nil, "flag -addr: the name must not include the leading '-'"},
+		{[]Flag{{Name: "addr"}, {Name: "addr"}}, nil, "flag addr: defined more than once"},
+		{[]Flag{{Name: "logfile"}}, nil, "flag logfile: defined more than once"},
+		{[]Flag{{Name: "log-file"}}, nil, "flag log-file: field LogFile is used by another flag"},
+		{[]Flag{{Name: "verbose", Aliases: []string{"v"}}, {Name: "version", Aliases: []string{"V"}}}, nil, ""},
+		{[]Flag{{Name: "verbose", Aliases: []string{"v"}}, {Name: "version", Aliases: []string{"v"}}}, nil, "flag v: defined more than once"},
+		{[]Flag{{Name: "verbose", Aliases: []string{"verbose"}}}, nil, "flag verbose: defined more than once"},
+		{[]Flag{{Name: "verbose", Aliases: []string{"-v"}}}, nil, "flag -v: the name must not include the leading '-'"},
+		{[]Flag{{Name: "verbose", Aliases: []string{""}}}, nil, "flag verbose: empty alias"},
+		{[]Flag{{Name: "addr", Env: "ADDR"}, {Name: "port", Env: "PORT"}}, nil, ""},
+		{[]Flag{{Name: "addr", Env: "ADDR"}, {Name: "host", Env: "ADDR"}}, nil, "flag host: env ADDR is used by another flag"},
+		{[]Flag{{Name: "addr", Env: "LISTEN-ADDR"}}, nil, `flag addr: env "LISTEN-ADDR": not a valid environment variable name`},
+		{[]Flag{{Name: "1st"}}, nil, `flag 1st: "1st" is not a valid field name`},
+		{[]Flag{{Name: "addr", Type: "[]string"}}, nil, "flag addr: unsupported type: []string"},
+		{[]Flag{{Name: "level", Type: "Level"}}, nil, ""},
+		{[]Flag{{Name: "level", Type: "Level", Default: "info"}}, nil, "flag level: Level: a default is not supported for flag.Value types"},
+		{[]Flag{{Name: "n", Type: "int", Default: "ten"}}, nil, `flag n: default "ten": strconv.ParseInt: parsing "ten": invalid syntax`},
+		{[]Flag{{Name: "ratio", Type: "float64", Default: "Inf"}}, nil, `flag ratio: default "Inf": not a finite number`},
+		{[]Flag{{Name: "ratio", Type: "float64", Default: "-infinity"}}, nil, `flag ratio: default "-infinity": not a finite number`},
+		{[]Flag{{Name: "ratio", Type: "float64", Default: "NaN"}}, nil, `flag ratio: default "NaN": not a finite number`},
+		{[]Flag{{Name: "ratio", Type: "float64", Default: "1e3"}}, nil, ""},
+		{[]Flag{{Name: "addr", Usage: "listen address\nhost:port"}}, nil, "flag addr: usage: must be a single line"},
+		{[]Flag{{Name: "addr", Comment: "the address\r\nto listen on"}}, nil, "flag addr: comment: must be a single line"},
+		{nil, []Command{{Name: "serve", Flags: []Flag{{Name: "addr", Comment: "the\naddress"}}}}, "command serve: flag addr: comment: must be a single line"},
+		{nil, []Command{{Name: "serve", Flags: []Flag{{Name: "logfile"}}}, {Name: "list-all"}}, ""},
+		{[]Flag{{Name: "addr"}}, []Command{{Name: "serve", Flags: []Flag{{Name: "addr"}}}}, ""},
+		{nil, []Command{{Name: ""}}, "command 0: no name"},
+		{nil, []Command{{Name: "serve"}, {Name: "serve"}}, "command serve: defined more than once"},
+		{nil, []Command{{Name: "list-all"}, {Name: "list_all"}}, "command list_all: identifier listAll is used by command list-all"},
+		{nil, []Command{{Name: "help"}}, "command help: help is reserved for the help command"},
+		{nil, []Command{{Name: "2nd"}}, `command 2nd: "2nd" is not usable as an identifier`},
+		{nil, []Command{{Name: "serve", Flags: []Flag{{Name: "addr"}, {Name: "addr"}}}}, "command serve: flag addr: defined more than once"},
+	}
+	for i, test := range tests {
+		p := Project{Flags: test.flags, Commands: test.commands}
+		err := p.Validate()
+		if err != nil {
+			if err.Error() != test.err {
+				t.Errorf("%d: got %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: got no error, want %q", i, test.err)
+		}
+	}
+}
+
+func TestLoadProject(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// no file is not an error
+	file, err := findProjectFile(dir, "foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if file != "" {
+		t.Errorf("got %q want \"\"", file)
+	}
+
+	def := `{
+	"name": "foo",
+	"owner": "Trillian",
+	"year": "1999",
+	"license": "MIT",
+	"flags": [
+		{"name": "addr", "aliases": ["a"], "default": ":8080", "usage": "listen address"}
+	]
+}`
+	err = ioutil.WriteFile(filepath.Join(dir, "foo.json"), []byte(def), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err = findProjectFile(dir, "foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if file != filepath.Join(dir, "foo.json") {
+		t.Errorf("got %q want %q", file, filepath.Join(dir, "foo.json"))
+	}
+
+	p, err := LoadProject(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if p.Name != "foo" || p.Owner != "Trillian" || p.Year != "1999" || p.License != "MIT" {
+		t.Errorf("got %+v", p)
+	}
+	if len(p.Flags) != 1 {
+		t.Fatalf("got %d flags want 1", len(p.Flags))
+	}
+	if !reflect.DeepEqual(p.Flags[0], Flag{Name: "addr", Aliases: []string{"a"}, Default: ":8080", Usage: "listen address"}) {
+		t.Errorf("got %+v", p.Flags[0])
+	}
+
+	// flags that were set are not changed
+	var a App
+	a.Owner = "Zaphod Beeblebrox"
+	a.Year = "1942"
+	p.Apply(&a, map[string]bool{"owner": true})
+	if a.Name != "foo" {
+		t.Errorf("name: got %q want %q", a.Name, "foo")
+	}
+	if a.Owner != "Zaphod Beeblebrox" {
+		t.Errorf("owner: got %q want %q", a.Owner, "Zaphod Beeblebrox")
+	}
+	if a.Year != "1999" {
+		t.Errorf("year: got %q want %q", a.Year, "1999")
+	}
+	if len(a.Flags) != 1 {
+		t.Errorf("flags: got %d want 1", len(a.Flags))
+	}
+
+	// an invalid definition is an error
+	err = ioutil.WriteFile(file, []byte(`{"flags": [{"name": "logfile"}]}`), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadProject(file)
+	if err == nil || !strings.HasSuffix(err.Error(), "flag logfile: defined more than once") {
+		t.Errorf("got %v want a defined more than once error", err)
+	}
+}
@@ -1,25 +1,21 @@
 package main
 
 import (
-	"bytes"
 	"flag"
 	"fmt"
-	"go/format"
-	"io/ioutil"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
 )
 
+// parseFlags sets the app's information using the flags, which have been
+// parsed, and the project definition.
 func parseFlags() {
-	flag.Parse()
 	var err error
 	if app.Path == "" {
 		app.Path, err = os.Getwd()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%s error: get WD: ", app.Name, err)
+			fmt.Fprintf(os.Stderr, "%s error: get WD: %s", app.Name, err)
 			os.Exit(1)
 		}
 	} else {
@@ -33,6 +29,32 @@ func parseFlags() {
 		}
 		app.Path = filepath.Join(gop, app.Path)
 	}
+	// load the project definition, if there is one; anything set by flag
+	// takes precedence.
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if set["owner"] {
+		app.ownerSource = ownerFlag
+	}
+	if cfgFile == "" {
+		cfgFile, err = findProjectFile(app.Path, filepath.Base(app.Path))
+		if err != nil {
+			log.Printf("error: %s", err)
+			os.Exit(1)
+		}
+	}
+	if cfgFile != "" {
+		p, err := LoadProject(cfgFile)
+		if err != nil {
+			log.Printf("error: %s", err)
+			os.Exit(1)
+		}
+		p.Apply(&app, set)
+		set["owner"] = set["owner"] || p.Owner != ""
+		set["year"] = set["year"] || p.Year != ""
+		set["holder"] = set["holder"] || len(p.Holders) > 0
+	}
+
 	// set the app name, if it isn't set
 	if app.Name == "" {
 		app.Name = filepath.Base(app.Path)
@@ -42,604 +64,291 @@ func parseFlags() {
 		app.Path = filepath.Join(app.Path, "cmd", app.Name)
 	}
 
-	app.License, err = LicenseFromString(license)
+	err = validHeaderStyle(app.Header)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s error: %s", app.Name, err)
+		log.Printf("error: %s", err)
 		os.Exit(1)
 	}
-}
 
-// generate does the actual work of creating the main.go and whatever else is
-// needed
-func (a *App) Generate() int {
-	// If a license was specified, copy it to the path.
-	if a.License != None {
-		err := a.CopyLicense()
+	// if no license was specified, use the license of the app's license file,
+	// if it has one.
+	if license == "" {
+		err = app.defaultLicense()
 		if err != nil {
-			log.Printf("copy %s: error: %s", a.License, err)
-			return 1
+			log.Printf("error: %s", err)
+			os.Exit(1)
 		}
 	}
 
-	// these are in separate funcs for testability
-	err := a.WriteMain()
+	expr, err := ParseLicenseExpr(license)
 	if err != nil {
-		log.Printf("%s: error: %s", mainFile, err)
-		return 1
+		log.Printf("error: %s", err)
+		os.Exit(1)
+	}
+	switch {
+	case expr == nil: // no license
+	case expr.IsLicense():
+		app.License = expr.License
+	default:
+		app.Expression = expr
 	}
 
-	err = a.WriteAppFile()
+	// unless they were set, keep the year and owner of the app's existing
+	// copyright, if it has one.
+	err = app.defaultCopyright(set)
 	if err != nil {
-		log.Printf("%s: error: %s", a.Name+"_main.go", err)
-		return 1
+		log.Printf("error: existing copyright: %s", err)
+		os.Exit(1)
 	}
-
-	return 0
+	// if there still isn't an owner, look for one; see discoverOwner.
+	app.defaultOwner()
 }
 
-func (a *App) WriteMain() error {
-	a.buf.Reset()
-
-	err := a.writeSLH() // write the Standard License Header, if there is one.
+// generate does the actual work of creating the main.go and whatever else is
+// needed. Everything is rendered before anything is written; if DryRun is
+// set, what would be written is reported instead and if Diff is set, the
+// generated files are diffed with the files on disk.
+func (a *App) Generate() int {
+	ops, err := a.planYears()
 	if err != nil {
-		return err
+		log.Printf("error: %s", err)
+		return 1
 	}
-
-	_, err = a.buf.WriteString("package main\nimport (\n\"flag\"\n\"log\"\n\"path/filepath\"\n\"os\"\n)\n\nvar app = filepath.Base(os.Args[0]) // name of application\n")
+	err = a.warnUnfilled()
 	if err != nil {
-		return err
+		log.Printf("error: %s", err)
+		return 1
 	}
 
-	// config
-	_, err = a.buf.WriteString("var cfg Config\n\ntype Config struct {\nLogFile string // output destination for logs; stderr is default\nf *os.File // logfile handle for close; this will be nil if output is stderr\n}\n")
-	if err != nil {
-		return err
+	if a.Diff {
+		return diffOps(ops)
 	}
 
-	// init
-	_, err = a.buf.WriteString("\nfunc init() {\nflag.StringVar(&cfg.LogFile, \"logfile\", \"stderr\", \"output destination for logs\")\n\nlog.SetPrefix(app + \": \")\n}\n")
-	if err != nil {
-		return err
+	if a.DryRun {
+		for _, op := range ops {
+			op.report(os.Stdout, a.Show)
+		}
+		return 0
 	}
 
-	// main
-	_, err = a.buf.WriteString("\nfunc main() {\nflag.Usage = usage\n\n// Process flags\nFlagParse()\n\nos.Exit(")
-	if err != nil {
-		return err
-	}
-	_, err = a.buf.WriteString(a.Name)
-	if err != nil {
-		return err
-	}
-	_, err = a.buf.WriteString("Main())\n}")
-	if err != nil {
-		return err
+	for _, op := range ops {
+		err = op.write()
+		if err != nil {
+			log.Printf("%s: error: %s", op.Path, err)
+			return 1
+		}
 	}
 
-	// fmt the code
-	fmtd, err := format.Source(a.buf.Bytes())
-	if err != nil {
-		return fmt.Errorf("fmt source: %s", err)
-	}
+	return 0
+}
+
+// these are in separate funcs for testability
 
-	// open the file and write
-	f, err := os.OpenFile(filepath.Join(a.Path, mainFile), os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0664)
+func (a *App) WriteMain() error {
+	op, err := a.mainOp()
 	if err != nil {
-		return fmt.Errorf("open failed: %s", err)
+		return err
 	}
-	defer f.Close()
+	return op.write()
+}
 
-	n, err := f.Write(fmtd)
+func (a *App) mainOp() (fileOp, error) {
+	fmtd, err := a.render(mainTmpl, a)
 	if err != nil {
-		return fmt.Errorf("write failed: %s", err)
+		return fileOp{}, err
 	}
-
-	fmt.Printf("%s: %d bytes were written to %s\n", exe, n, filepath.Join(a.Path, mainFile))
-	return nil
+	return generatedOp(filepath.Join(a.Path, mainFile), fmtd)
 }
 
 // write the app.go file.
 func (a *App) WriteAppFile() error {
-	a.buf.Reset()
-
-	appFile := filepath.Join(a.Path, a.Name+"_main.go")
-	// if the app file already exists; don't modify to prevent overwriting any user code.
-	_, err := os.Stat(appFile)
-	if err == nil {
-		return nil
-	}
-	if err != nil && !os.IsNotExist(err) { // if the error wasn't IsNotExist, return the err.
-		return fmt.Errorf("%s: %s", appFile, err)
-	}
-
-	_, err = a.buf.WriteString("package main\n\nimport(\n\"flag\"\n\"fmt\"\n\"os\"\n)\n")
+	op, err := a.appFileOp()
 	if err != nil {
 		return err
 	}
+	return op.write()
+}
 
-	err = a.WriteUsage()
-	if err != nil {
-		return err
-	}
+func (a *App) appFileOp() (fileOp, error) {
+	appFile := filepath.Join(a.Path, a.Name+"_main.go")
+	return userOp(appFile, func() ([]byte, error) {
+		return a.render(appMainTmpl, a)
+	})
+}
 
-	err = a.WriteFlagParse()
+// write the file of a command: the command's usage and main funcs.
+func (a *App) WriteCommandFile(c Command) error {
+	op, err := a.commandFileOp(c)
 	if err != nil {
 		return err
 	}
+	return op.write()
+}
 
-	_, err = a.buf.WriteString("\n\nfunc ")
-	if err != nil {
-		return err
-	}
+func (a *App) commandFileOp(c Command) (fileOp, error) {
+	cmdFile := filepath.Join(a.Path, c.File())
+	return userOp(cmdFile, func() ([]byte, error) {
+		return a.render(cmdTmpl, c)
+	})
+}
 
-	_, err = a.buf.WriteString(a.Name)
+// CopyLicense copies the license text. Any placeholders in the text are
+// replaced with the actual value; if applicable. If the license is the Apache
+// License 2.0, the NOTICE file is written too.
+func (a *App) CopyLicense() error {
+	op, err := a.licenseOp()
 	if err != nil {
 		return err
 	}
-
-	_, err = a.buf.WriteString("Main() int {\nif cfg.f != nil {\ndefer cfg.f.Close() // make sure the logfile is closed if there is one\n}\n\nfmt.Printf(\"%s: hello, world\\n\", app)\n\nreturn 0\n}\n")
-	if err != nil {
+	err = op.write()
+	if err != nil || !a.usesApache() {
 		return err
 	}
-
-	// fmt the code
-	fmtd, err := format.Source(a.buf.Bytes())
-	if err != nil {
-		return fmt.Errorf("fmt source: %s", err)
-	}
-
-	// open the file and write
-	f, err := os.OpenFile(appFile, os.O_CREATE|os.O_RDWR, 0664)
+	op, err = a.noticeOp()
 	if err != nil {
-		return fmt.Errorf("open failed: %s", err)
+		return fmt.Errorf("NOTICE: %s", err)
 	}
-	defer f.Close()
-
-	n, err := f.Write(fmtd)
-	if err != nil {
-		return fmt.Errorf("write failed: %s", err)
-	}
-
-	fmt.Printf("%s: %d bytes were written to %s\n", exe, n, appFile)
-	return nil
+	return op.write()
 }
 
-// write the usage func
-func (a *App) WriteUsage() error {
-	_, err := a.buf.WriteString("\n")
-	if err != nil {
-		return fmt.Errorf("usage func: %s", err)
-	}
-
-	cmt := "usage is the usage func for flag.Usage."
-	cmt, err = a.wrapper.Line(cmt)
-	if err != nil {
-		return fmt.Errorf("usage func: %s", err)
-	}
-
-	_, err = a.buf.WriteString(cmt)
-	if err != nil {
-		return fmt.Errorf("usage func: %s", err)
-	}
-
-	_, err = a.buf.WriteString("\nfunc usage() {\n")
-	if err != nil {
-		return fmt.Errorf("usage func: %s", err)
-	}
-
-	_, err = a.buf.WriteString("fmt.Fprint(os.Stderr, \"Usage:\\n\")\nfmt.Fprintf(os.Stderr, \"  %s [FLAGS] \\n\", app)\nfmt.Fprint(os.Stderr, \"\\n\")\n")
-	if err != nil {
-		return fmt.Errorf("usage func: %s", err)
-	}
-
-	_, err = a.buf.WriteString("fmt.Fprintf(os.Stderr, \"Insert information about %s here\\n\", app)\nfmt.Fprint(os.Stderr, \"\\n\")\nfmt.Fprint(os.Stderr, \"Options:\\n\")\nflag.PrintDefaults()\n")
-	if err != nil {
-		return fmt.Errorf("usage func: %s", err)
-	}
-
-	_, err = a.buf.WriteString("}\n\n")
+func (a *App) licenseOp() (fileOp, error) {
+	b, err := a.licenseText()
 	if err != nil {
-		return fmt.Errorf("usage func: %s", err)
+		return fileOp{}, err
 	}
-	return nil
+	return generatedOp(filepath.Join(a.Path, "LICENSE"), b)
 }
 
-// write the FlagParse func: parseFlag os.Exit's on any error.
-func (a *App) WriteFlagParse() error {
-	cmt := "FlagParse handles flag parsing, validation, and any side affects of flag states. Errors or invalid states should result in printing a message to os.Stderr and an os.Exit() with a non-zero int."
-	cmt, err := a.wrapper.Line(cmt)
-	if err != nil {
-		return fmt.Errorf("FlagParse func: %s", err)
-	}
-
-	_, err = a.buf.WriteString(cmt)
-	if err != nil {
-		return fmt.Errorf("FlagParse func: %s", err)
-	}
-
-	_, err = a.buf.WriteString("\nfunc FlagParse() {\nvar err error\n\nflag.Parse()\n\n")
-	if err != nil {
-		return fmt.Errorf("FlagParse func: %s", err)
-	}
-
-	// log
-	_, err = a.buf.WriteString("if cfg.LogFile != \"\" && cfg.LogFile != \"stdout\" {  // open the logfile if one is specified\ncfg.f, err = os.OpenFile(cfg.LogFile, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0664)\n")
-	if err != nil {
-		return fmt.Errorf("FlagParse func: %s", err)
-	}
-	_, err = a.buf.WriteString("if err != nil {\nfmt.Fprintf(os.Stderr, \"%s: open logfile: %s\", app, err)\nos.Exit(1)\n}\n}\n}\n")
+// licenseText returns the full text of the license with its placeholders
+// replaced. An exception's full text is in the license corpus too; its ID is
+// used as the license.
+func (a *App) licenseText() ([]byte, error) {
+	name := licenseFiles(a.License)[0]
+	b, _, err := readLicenseFile(name)
 	if err != nil {
-		return fmt.Errorf("FlagParse func: %s", err)
+		if os.IsNotExist(err) { // an SPDX license that isn't in the corpus
+			return nil, fmt.Errorf("the license's text isn't in the license corpus; add it, as %s, to the license override directory, see -licensedir", name)
+		}
+		return nil, fmt.Errorf("read license file: %s", err)
 	}
 
-	return nil
-
+	// if the license has any placeholders replace them with values
+	b, _ = a.fillPlaceholders(b)
+	return b, nil
 }
 
-// CopyLicense copies the license text. Any placeholders in the text are
-// replaced with the actual value; if applicable.
-func (a *App) CopyLicense() error {
-	lFile := strings.ToLower(a.License.ID())
-
-	srcFile := filepath.Join(quinePath, licenseDir, lFile)
-	b, err := ioutil.ReadFile(srcFile)
-	if err != nil {
-		return fmt.Errorf("reade license file: %s", err)
-	}
-
-	// if the license has any placeholders replace them with values
-	b = a.replaceLicensePlaceholders(b)
-	dstFile := filepath.Join(a.Path, "LICENSE")
-	dst, err := os.OpenFile(dstFile, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0664)
-	if err != nil {
-		return fmt.Errorf("open dest. file: %s", err)
+// checkLicenseText checks that the full text of the app's license, or of each
+// of its license expression's licenses and exceptions, is in the license
+// corpus, so that an app isn't planned with a license it can't write.
+func (a *App) checkLicenseText() error {
+	var ls []License
+	switch {
+	case a.Expression != nil:
+		ls = a.Expression.Licenses()
+		for _, x := range a.Expression.Exceptions() {
+			ls = append(ls, License(x))
+		}
+	case a.License != "":
+		ls = []License{a.License}
 	}
-	defer dst.Close()
-
-	n, err := dst.Write(b)
-	if err != nil {
-		return fmt.Errorf("write license to %s: %s", dstFile, err)
+	la := *a
+	for _, l := range ls {
+		la.License = l
+		_, err := la.licenseText()
+		if err != nil {
+			return fmt.Errorf("%s: %s", l.ID(), err)
+		}
 	}
-
-	fmt.Printf("%s: %s copied to %s; %d bytes written\n", app, lFile, dstFile, n)
 	return nil
 }
 
-// not all licenses have placeholders to replace.
-func (a *App) replaceLicensePlaceholders(b []byte) []byte {
-	switch a.License {
-	case BSD2Clause:
-		return a.replaceBSD2ClauseLicensePlaceholders(b)
-	case BSD3Clause:
-		return a.replaceBSD3ClauseLicensePlaceholders(b)
-	case MIT:
-		return a.replaceMITLicensePlaceholders(b)
-	}
-
-	return b
-}
-
-func (a *App) replaceBSD2ClauseLicensePlaceholders(b []byte) []byte {
-	// if owner and year aren't set, nothing to do.
-	if a.Owner == "" && a.Year == "" {
-		return b
-	}
-
-	// make out == the len of the license when replacements are done
-	y, o := 6, 7 // <year> <owner>
-	if a.Year != "" {
-		y = len(a.Year)
-	}
-	if a.Owner != "" {
-		o = len(a.Owner)
-	}
-
-	out := make([]byte, 0, len(b)-13+y+o)
-	out = append(out, b[:14]...)
-	// year
-	if a.Year == "" {
-		out = append(out, b[14:20]...)
-	} else {
-		out = append(out, []byte(a.Year)...)
+// expressionOps returns the ops of the license expression's files: the full
+// text of each of its licenses and exceptions, in LICENSE-<ID>.
+func (a *App) expressionOps() ([]fileOp, error) {
+	var ops []fileOp
+	la := *a
+	for _, l := range a.Expression.Licenses() {
+		la.License = l
+		b, err := la.licenseText()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", l, err)
+		}
+		op, err := generatedOp(filepath.Join(a.Path, exprFile(l.ID())), b)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
 	}
-
-	out = append(out, ' ')
-
-	// owner
-	if a.Owner == "" {
-		out = append(out, b[21:28]...)
-	} else {
-		out = append(out, []byte(a.Owner)...)
+	for _, x := range a.Expression.Exceptions() {
+		la.License = License(x)
+		b, err := la.licenseText()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", x, err)
+		}
+		op, err := generatedOp(filepath.Join(a.Path, exprFile(x)), b)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
 	}
-
-	out = append(out, b[28:]...)
-
-	return out
+	return ops, nil
 }
 
-func (a *App) replaceBSD3ClauseLicensePlaceholders(b []byte) []byte {
-	// if owner and year aren't set, nothing to do.
-	if a.Owner == "" && a.Year == "" {
-		return b
-	}
-
-	// make out == the len of the license when replacements are done
-	y, o := 6, 7 // <year> <owner>
-	if a.Year != "" {
-		y = len(a.Year)
-	}
-	if a.Owner != "" {
-		o = len(a.Owner)
-	}
-
-	out := make([]byte, 0, len(b)-13+y+o)
-	out = append(out, b[:14]...)
-	// year
-	if a.Year == "" {
-		out = append(out, b[14:20]...)
-	} else {
-		out = append(out, []byte(a.Year)...)
-	}
-
-	out = append(out, ' ')
-
-	// owner
-	if a.Owner == "" {
-		out = append(out, b[21:29]...)
-	} else {
-		out = append(out, []byte(a.Owner)...)
-	}
-
-	out = append(out, b[29:]...)
-
-	return out
+// If a license was specified, open its SLH, Standard License Header, file, if
+// it has one and return it as a comment, for main.go. If the license doesn't
+// have an SLH file, the SPDX License List's standard header is used, if it has
+// one.
+func (a *App) slh() (string, error) {
+	b, err := a.slhText()
+	if err != nil || b == nil {
+		return "", err
+	}
+	return a.slhComment(b)
 }
 
-func (a *App) replaceMITLicensePlaceholders(b []byte) []byte {
-	// if owner and year aren't set, nothing to do.
-	if a.Owner == "" && a.Year == "" {
-		return b
-	}
-
-	// make out == the len of the license when replacements are done
-	y, o := 6, 19 // <year> <owner>
-	if a.Year != "" {
-		y = len(a.Year)
-	}
-	if a.Owner != "" {
-		o = len(a.Owner)
-	}
-
-	out := make([]byte, 0, len(b)-25+y+o)
-
-	out = append(out, b[:26]...)
-	// year
-	if a.Year == "" {
-		out = append(out, b[26:32]...)
-	} else {
-		out = append(out, []byte(a.Year)...)
-	}
-
-	out = append(out, ' ')
-	// owner
-	if a.Owner == "" {
-		out = append(out, b[33:52]...)
-	} else {
-		out = append(out, []byte(a.Owner)...)
+// slhText returns the app's SLH with its placeholders; see slh. If there is
+// no SLH, nil is returned.
+func (a *App) slhText() ([]byte, error) {
+	if a.Expression != nil {
+		h, err := a.Expression.header()
+		if err != nil {
+			return nil, fmt.Errorf("license expression header: %s", err)
+		}
+		return []byte(h), nil
 	}
-
-	out = append(out, b[53:]...)
-
-	return out
-}
-
-// If a license was specified, open its SLH, Standard License Header, file, if
-// it has one and write it to main.go.
-func (a *App) writeSLH() error {
 	if a.License == None { // if no license is specified nothing to do
-		return nil
+		return nil, nil
 	}
 
 	// read the slh file
-	slhFile := filepath.Join(quinePath, licenseDir, strings.ToLower(a.License.ID())+".slh")
-	b, err := ioutil.ReadFile(slhFile)
+	slhFile := licenseFiles(a.License)[1]
+	b, _, err := readLicenseFile(slhFile)
 	if err != nil {
-		if os.IsNotExist(err) { // not all licenses have SLHs, this is not an error state
-			return nil
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("SLH file: read %s: %s", slhFile, err) // return any other error
 		}
-		return fmt.Errorf("SLH file: read %s: %s", slhFile, err) // return any other error
-	}
-
-	// MPl-2.0 is used as is.
-	switch a.License {
-	case Apache20:
-		b = a.replaceApache20SLHPlaceholders(b)
-	case GPL20:
-		b = a.replaceGPL20SLHPlaceholders(b)
-	case GPL30:
-		b = a.replaceGPL30SLHPlaceholders(b)
-	case LGPL20, LGPL21: // format and location of the placeholders are the same for both
-		b = a.replaceLGPL2SLHPlaceholders(b)
-	}
-
-	cmt, err := a.wrapper.Line(string(b))
-	if err != nil {
-		return fmt.Errorf("SLH file: format as comment: %s", err)
-	}
-
-	_, err = a.buf.WriteString(cmt)
-	if err != nil {
-		return fmt.Errorf("SLH file: write: %s", err)
-	}
-	_, err = a.buf.WriteString("\n\n")
-	if err != nil {
-		return fmt.Errorf("SLH file: write \n: %s", err)
-	}
-	return nil
-}
-
-func (a *App) replaceApache20SLHPlaceholders(b []byte) []byte {
-	// if owner and year aren't set, nothing to do.
-	if a.Owner == "" && a.Year == "" {
-		return b
-	}
-
-	// make out == the len of the license when replacements are done
-	y, o := 6, 26 // <year> <owner>
-	if a.Year != "" {
-		y = len(a.Year)
-	}
-	if a.Owner != "" {
-		o = len(a.Owner)
-	}
-
-	out := make([]byte, 0, len(b)-33+y+o)
-	out = append(out, b[:10]...)
-	// year
-	if a.Year == "" {
-		out = append(out, b[10:16]...)
-	} else {
-		out = append(out, []byte(a.Year)...)
-	}
-
-	out = append(out, ' ')
-
-	// owner
-	if a.Owner == "" {
-		out = append(out, b[17:42]...)
-	} else {
-		out = append(out, []byte(a.Owner)...)
-	}
-
-	out = append(out, b[42:]...)
-
-	return out
-}
-
-func (a *App) replaceGPL20SLHPlaceholders(b []byte) []byte {
-	// if owner and year aren't set, nothing to do.
-	if a.Owner == "" && a.Year == "" {
-		return b
-	}
-
-	// make out == the len of the license when replacements are done
-	y, o := 4, 14 // yyy name of author
-	if a.Year != "" {
-		y = len(a.Year)
-	}
-	if a.Owner != "" {
-		o = len(a.Owner)
-	}
-
-	out := make([]byte, 0, len(b)-19+y+o)
-	out = append(out, b[:14]...)
-	// year
-	if a.Year == "" {
-		out = append(out, b[14:18]...)
-	} else {
-		out = append(out, []byte(a.Year)...)
-	}
-
-	out = append(out, ' ')
-
-	// owner
-	if a.Owner == "" {
-		out = append(out, b[19:33]...)
-	} else {
-		out = append(out, []byte(a.Owner)...)
-	}
-
-	out = append(out, b[33:]...)
-
-	return out
-}
-
-func (a *App) replaceGPL30SLHPlaceholders(b []byte) []byte {
-	// if owner and year aren't set, nothing to do.
-	if a.Owner == "" && a.Year == "" {
-		return b
-	}
-
-	// make out == the len of the license when replacements are done
-	y, o := 6, 16 // yyy name of author
-	if a.Year != "" {
-		y = len(a.Year)
-	}
-	if a.Owner != "" {
-		o = len(a.Owner)
-	}
-
-	out := make([]byte, 0, len(b)-23+y+o)
-	out = append(out, b[:14]...)
-	// year
-	if a.Year == "" {
-		out = append(out, b[14:20]...)
-	} else {
-		out = append(out, []byte(a.Year)...)
-	}
-
-	out = append(out, ' ')
-
-	// owner
-	if a.Owner == "" {
-		out = append(out, b[21:37]...)
-	} else {
-		out = append(out, []byte(a.Owner)...)
+		idx, err := spdxLicenses()
+		if err != nil {
+			return nil, fmt.Errorf("SLH: %s", err)
+		}
+		sl, _ := idx.Lookup(a.License)
+		if sl.Header == "" { // not all licenses have SLHs, this is not an error state
+			return nil, nil
+		}
+		b = []byte(sl.Header)
 	}
-
-	out = append(out, b[37:]...)
-
-	return out
+	return b, nil
 }
 
-func (a *App) replaceLGPL2SLHPlaceholders(b []byte) []byte {
-	// if owner and year aren't set, nothing to do.
-	if a.Owner == "" && a.Year == "" {
-		return b
-	}
-
-	// make out == the len of the license when replacements are done
-	y, o := 4, 14 // yyy name of author
-	if a.Year != "" {
-		y = len(a.Year)
-	}
-	if a.Owner != "" {
-		o = len(a.Owner)
-	}
-
-	out := make([]byte, 0, len(b)-19+y+o)
-	out = append(out, b[:14]...)
-	// year
-	if a.Year == "" {
-		out = append(out, b[14:18]...)
-	} else {
-		out = append(out, []byte(a.Year)...)
-	}
-
-	out = append(out, ' ')
-
-	// owner
-	if a.Owner == "" {
-		out = append(out, b[19:33]...)
-	} else {
-		out = append(out, []byte(a.Owner)...)
-	}
-
-	out = append(out, b[33:]...)
-
-	return out
-}
+// slhComment returns the SLH, b, as a comment.
+func (a *App) slhComment(b []byte) (string, error) {
+	// if the SLH has any placeholders replace them with values
+	b, _ = a.fillPlaceholders(b)
 
-// returns Git's globally configured user.name or an error. This assumes that
-// git is installed.
-func githubUsername() (gituser string, err error) {
-	cmd := exec.Command("git", "config", "--global", "user.name")
-	var buf bytes.Buffer
-	cmd.Stdout = &buf
-	err = cmd.Run()
+	cmt, err := a.wrapper.Line(string(b))
 	if err != nil {
-		return "", fmt.Errorf("get git global user.name: %s", err)
+		return "", fmt.Errorf("SLH file: format as comment: %s", err)
 	}
 
-	return string(bytes.TrimRight(buf.Bytes(), "\n")), nil
+	return cmt + "\n\n", nil
 }
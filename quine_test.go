@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bytes"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -23,9 +22,11 @@ var expectedMain = `package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 var app = filepath.Base(os.Args[0]) // name of application
@@ -43,13 +44,106 @@ func init() {
 }
 
 func main() {
-	flag.usage = Usage
+	flag.Usage = usage
 
 	// Process flags
 	FlagParse()
 
 	os.Exit(testMain())
 }
+
+// commandLine are the app's flags.
+var commandLine = flagSet{
+	FlagSet: flag.CommandLine,
+	names: [][]string{
+		{"logfile"},
+	},
+	env: map[string]string{},
+}
+` + expectedFlagSet
+
+// expectedFlagSet is the flagSet type and its methods that end every
+// main.go.
+var expectedFlagSet = `
+// flagSet is a flag.FlagSet along with the names and environment variables
+// of its flags.
+type flagSet struct {
+	*flag.FlagSet
+	names [][]string        // the names of the flags; a flag's aliases follow its name
+	env   map[string]string // the environment variables that can set the flags, by flag name
+}
+
+// printDefaults prints the default values of all the app's flags to
+// os.Stderr. It is like flag.PrintDefaults except that a flag's aliases are
+// listed on the same line.
+func printDefaults() {
+	commandLine.printDefaults()
+}
+
+// applyEnv sets each of the app's flags that wasn't set on the command line
+// to the value of its environment variable, if the flag has one and it is set.
+func applyEnv() error {
+	return commandLine.applyEnv()
+}
+
+// printDefaults prints the default values of all the flags in the set to
+// os.Stderr. It is like flag.PrintDefaults except that a flag's aliases are
+// listed on the same line.
+func (fs *flagSet) printDefaults() {
+	for _, names := range fs.names {
+		f := fs.Lookup(names[0])
+		s := "  -" + strings.Join(names, ", -")
+		name, usage := flag.UnquoteUsage(f)
+		if len(name) > 0 {
+			s += " " + name
+		}
+		s += "\n    \t" + strings.Replace(usage, "\n", "\n    \t", -1)
+		if env, ok := fs.env[names[0]]; ok {
+			s += " (env $" + env + ")"
+		}
+		switch f.DefValue {
+		case "", "0", "false", "0s": // zero values aren't printed
+		default:
+			format := " (default %v)"
+			if v, ok := f.Value.(flag.Getter); ok {
+				if _, ok := v.Get().(string); ok {
+					format = " (default %q)"
+				}
+			}
+			s += fmt.Sprintf(format, f.DefValue)
+		}
+		fmt.Fprintln(os.Stderr, s)
+	}
+}
+
+// applyEnv sets each flag in the set that wasn't set on the command line to
+// the value of its environment variable, if the flag has one and it is set.
+func (fs *flagSet) applyEnv() error {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, names := range fs.names {
+		env, ok := fs.env[names[0]]
+		if !ok {
+			continue
+		}
+		var isSet bool
+		for _, name := range names {
+			isSet = isSet || set[name]
+		}
+		if isSet {
+			continue
+		}
+		v, ok := os.LookupEnv(env)
+		if !ok {
+			continue
+		}
+		err := fs.Set(names[0], v)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for %s: %s", v, env, err)
+		}
+	}
+	return nil
+}
 `
 
 func TestWriteMain(t *testing.T) {
@@ -95,7 +189,7 @@ func TestWriteMain(t *testing.T) {
 		{GPL30, `// Copyright (C) 1999 Trillian
 // This program is free software: you can redistribute it and/or modify it
 // under the terms of the GNU General Public License as published by the Free
-// Software Foundation, version.
+// Software Foundation, version 3.
 //
 // This program is distributed in the hope that it will be useful, but WITHOUT
 // ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
@@ -142,7 +236,22 @@ func TestWriteMain(t *testing.T) {
 
 `,
 		},
-		{LGPL30, ""},
+		{LGPL30, `// Copyright (C) 1999 Trillian
+// This library is free software: you can redistribute it and/or modify it
+// under the terms of the GNU Lesser General Public License as published by the
+// Free Software Foundation, version 3.
+//
+// This library is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License
+// for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with this library. If not, see <http://www.gnu.org/licenses/>.
+//
+
+`,
+		},
 		{MIT, ""},
 		{MPL20, `// This Source Code Form is subject to the terms of the Mozilla Public License,
 // v. 2.0. If a copy of the MPL was not distributed with this file, You can
@@ -173,6 +282,13 @@ func TestWriteMain(t *testing.T) {
 			t.Errorf("unexpected error readging %s: %q", filepath.Join(lapp.Path, mainFile), err)
 			continue
 		}
+		// licenses with CLI notices are checked by TestWriteMainNotice.
+		if _, ok := noticeFlags[test.license]; ok {
+			if !strings.HasPrefix(string(b), test.expected+"package main\n") {
+				t.Errorf("%d: got %q\nwant prefix %q", i, string(b), test.expected)
+			}
+			continue
+		}
 		wants := strings.Split(test.expected+expectedMain, "\n")
 		gots := strings.Split(string(b), "\n")
 		if len(gots) != len(wants) {
@@ -205,7 +321,7 @@ func usage() {
 	fmt.Fprintf(os.Stderr, "Insert information about %s here\n", app)
 	fmt.Fprint(os.Stderr, "\n")
 	fmt.Fprint(os.Stderr, "Options:\n")
-	flag.PrintDefaults()
+	printDefaults()
 }
 
 // FlagParse handles flag parsing, validation, and any side affects of flag
@@ -216,6 +332,12 @@ func FlagParse() {
 
 	flag.Parse()
 
+	err = applyEnv() // flags that weren't set may be set by environment variables
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", app, err)
+		os.Exit(1)
+	}
+
 	if cfg.LogFile != "" && cfg.LogFile != "stdout" { // open the logfile if one is specified
 		cfg.f, err = os.OpenFile(cfg.LogFile, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0664)
 		if err != nil {
@@ -261,289 +383,339 @@ func testMain() int {
 	}
 }
 
-func TestReplaceBSD2ClauseLicensePlaceholders(t *testing.T) {
-	// only test the first line
-	tests := []struct {
-		owner    string
-		year     string
-		expected string
-	}{
-		{"", "", "Copyright (c) <year> <owner> All rights reserved."},
-		{"Zaphod Beeblebrox", "", "Copyright (c) <year> Zaphod Beeblebrox All rights reserved."},
-		{"", "1942", "Copyright (c) 1942 <owner> All rights reserved."},
-		{"Zaphod Beeblebrox", "1942", "Copyright (c) 1942 Zaphod Beeblebrox All rights reserved."},
-	}
-	b, err := ioutil.ReadFile(filepath.Join("license", strings.ToLower(BSD2Clause.ID())))
-	if err != nil {
-		t.Errorf("unexpected error: %q", err)
-		return
-	}
+func TestWriteMainFlags(t *testing.T) {
+	expected := `package main
 
-	a := app
-	for i, test := range tests {
-		a.Owner = test.owner
-		a.Year = test.year
-		v := a.replaceBSD2ClauseLicensePlaceholders(b)
-		ndx := bytes.IndexByte(v, '\n')
-		if ndx < 0 {
-			t.Errorf("%d: expected to find a \n; none found", i)
-			continue
-		}
-		line := string(v[:ndx])
-		if line != test.expected {
-			t.Errorf("%d: got %q want %q", i, line, test.expected)
-		}
-	}
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var app = filepath.Base(os.Args[0]) // name of application
+var cfg Config
+
+type Config struct {
+	LogFile string   // output destination for logs; stderr is default
+	f       *os.File // logfile handle for close; this will be nil if output is stderr
+	Addr    string   // listen address
+	Name    string
+	Verbose bool          // verbose output
+	Timeout time.Duration // how long to wait for the server
+	Level   Level         // log level
 }
 
-func TestReplaceBSD3ClauseLicensePlaceholders(t *testing.T) {
-	// only test the first line
-	tests := []struct {
-		owner    string
-		year     string
-		expected string
-	}{
-		{"", "", "Copyright (c) <year> <owner> . All rights reserved."},
-		{"Zaphod Beeblebrox", "", "Copyright (c) <year> Zaphod Beeblebrox. All rights reserved."},
-		{"", "1942", "Copyright (c) 1942 <owner> . All rights reserved."},
-		{"Zaphod Beeblebrox", "1942", "Copyright (c) 1942 Zaphod Beeblebrox. All rights reserved."},
+func init() {
+	flag.StringVar(&cfg.LogFile, "logfile", "stderr", "output destination for logs")
+	flag.StringVar(&cfg.Addr, "addr", ":8080", "listen address")
+	flag.StringVar(&cfg.Name, "name", "", "")
+	flag.BoolVar(&cfg.Verbose, "verbose", false, "verbose output")
+	flag.BoolVar(&cfg.Verbose, "v", false, "verbose output")
+	flag.DurationVar(&cfg.Timeout, "timeout", 30*time.Second, "server timeout")
+	flag.Var(&cfg.Level, "level", "log level")
+
+	log.SetPrefix(app + ": ")
+}
+
+func main() {
+	flag.Usage = usage
+
+	// Process flags
+	FlagParse()
+
+	os.Exit(testMain())
+}
+
+// commandLine are the app's flags.
+var commandLine = flagSet{
+	FlagSet: flag.CommandLine,
+	names: [][]string{
+		{"logfile"},
+		{"addr"},
+		{"name"},
+		{"verbose", "v"},
+		{"timeout"},
+		{"level"},
+	},
+	env: map[string]string{
+		"addr":    "TEST_ADDR",
+		"timeout": "TEST_TIMEOUT",
+	},
+}
+` + expectedFlagSet
+	var err error
+	lapp := app
+	lapp.Path, err = ioutil.TempDir("", "quine")
+	if err != nil {
+		panic(err)
 	}
-	b, err := ioutil.ReadFile(filepath.Join("license", strings.ToLower(BSD3Clause.ID())))
+	lapp.License = None
+	lapp.Flags = []Flag{
+		{Name: "addr", Default: ":8080", Usage: "listen address", Env: "ADDR"},
+		{Name: "name", Type: "string"},
+		{Name: "verbose", Aliases: []string{"v"}, Type: "bool", Usage: "verbose output"},
+		{Name: "timeout", Type: "duration", Default: "30s", Usage: "server timeout", Comment: "how long to wait for the server", Env: "TIMEOUT"},
+		{Name: "level", Type: "Level", Usage: "log level"},
+	}
+	err = lapp.WriteMain()
 	if err != nil {
-		t.Errorf("unexpected error: %q", err)
-		return
+		t.Fatalf("unexpected error: %s", err)
 	}
-
-	a := app
-	for i, test := range tests {
-		a.Owner = test.owner
-		a.Year = test.year
-		v := a.replaceBSD3ClauseLicensePlaceholders(b)
-		ndx := bytes.IndexByte(v, '\n')
-		if ndx < 0 {
-			t.Errorf("%d: expected to find a \n; none found", i)
-			continue
-		}
-		line := string(v[:ndx])
-		if line != test.expected {
-			t.Errorf("%d: got %q want %q", i, line, test.expected)
+	b, err := ioutil.ReadFile(filepath.Join(lapp.Path, mainFile))
+	if err != nil {
+		t.Fatalf("unexpected error reading %s: %q", filepath.Join(lapp.Path, mainFile), err)
+	}
+	gots := strings.Split(string(b), "\n")
+	wants := strings.Split(expected, "\n")
+	if len(gots) != len(wants) {
+		t.Fatalf("got %d lines want %d\ngot %q\nwant %q", len(gots), len(wants), string(b), expected)
+	}
+	for i, got := range gots {
+		if got != wants[i] {
+			t.Errorf("%d: got %q\nwant %q", i, got, wants[i])
 		}
 	}
 }
 
-func TestReplaceMITLicensePlaceholders(t *testing.T) {
-	// only test the first line
-	tests := []struct {
-		owner    string
-		year     string
-		expected string
-	}{
-		{"", "", "Copyright (c) <year> <copyright holders>"},
-		{"Zaphod Beeblebrox", "", "Copyright (c) <year> Zaphod Beeblebrox"},
-		{"", "1942", "Copyright (c) 1942 <copyright holders>"},
-		{"Zaphod Beeblebrox", "1942", "Copyright (c) 1942 Zaphod Beeblebrox"},
+func TestWriteMainCommands(t *testing.T) {
+	var err error
+	lapp := app
+	lapp.Path, err = ioutil.TempDir("", "quine")
+	if err != nil {
+		panic(err)
+	}
+	lapp.License = None
+	lapp.Commands = []Command{
+		{Name: "serve", Usage: "run the server", Flags: []Flag{{Name: "addr", Default: ":8080", Usage: "listen address", Env: "ADDR"}}},
+		{Name: "list-all"},
 	}
-	b, err := ioutil.ReadFile(filepath.Join("license", strings.ToLower(MIT.ID())))
+	err = lapp.WriteMain()
 	if err != nil {
-		t.Errorf("unexpected error: %q", err)
-		return
+		t.Fatalf("unexpected error: %s", err)
 	}
-
-	a := app
-	for i, test := range tests {
-		a.Owner = test.owner
-		a.Year = test.year
-		v := a.replaceMITLicensePlaceholders(b)
-		start := bytes.IndexByte(v, '\n')
-		if start < 0 {
-			t.Errorf("%d: expected to find a \n; none found", i)
-			continue
-		}
-		start++
-		end := bytes.IndexByte(v[start:], '\n')
-		if end < 0 {
-			t.Errorf("%d: expected to find a \n; none found", i)
-			continue
+	b, err := ioutil.ReadFile(filepath.Join(lapp.Path, mainFile))
+	if err != nil {
+		t.Fatalf("unexpected error reading %s: %q", filepath.Join(lapp.Path, mainFile), err)
+	}
+	// the rest of main.go is the same as an app without commands.
+	wants := []string{
+		"var serveCfg serveConfig\n\n// serveConfig is the configuration of the serve command.\ntype serveConfig struct {\n\tAddr string // listen address\n}\n",
+		"var listAllCfg listAllConfig\n\n// listAllConfig is the configuration of the list-all command.\ntype listAllConfig struct {\n}\n",
+		"\n\tserveFlags.StringVar(&serveCfg.Addr, \"addr\", \":8080\", \"listen address\")\n\tserveFlags.Usage = serveUsage\n\n\tlistAllFlags.Usage = listAllUsage\n",
+		"\tos.Exit(run(flag.Args()))\n}\n",
+		"var commands = []struct {\n\tname  string\n\tdesc  string // a short description of the command\n\tmain  func(args []string) int\n\tusage func()\n}{\n\t{\"serve\", \"run the server\", serveMain, serveUsage},\n\t{\"list-all\", \"\", listAllMain, listAllUsage},\n}\n",
+		"func run(args []string) int {\n",
+		"func printCommands() {\n",
+		"// serveFlags are the serve command's flags.\nvar serveFlags = flagSet{\n\tFlagSet: flag.NewFlagSet(\"serve\", flag.ExitOnError),\n\tnames: [][]string{\n\t\t{\"addr\"},\n\t},\n\tenv: map[string]string{\n\t\t\"addr\": \"TEST_SERVE_ADDR\",\n\t},\n}\n",
+	}
+	for i, want := range wants {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("%d: %q not found in %q", i, want, string(b))
 		}
-		end += start
+	}
+	if strings.Contains(string(b), "testMain()") {
+		t.Error("an app with commands should not call the app's main func")
+	}
+}
 
-		line := string(v[start:end])
-		if line != test.expected {
-			t.Errorf("%d: got %q want %q", i, line, test.expected)
-		}
+func TestWriteCommandFile(t *testing.T) {
+	expected := `package main
 
-	}
+import (
+	"fmt"
+	"os"
+)
+
+// listAllUsage is the usage func for the list-all command's flags.
+func listAllUsage() {
+	fmt.Fprint(os.Stderr, "Usage:\n")
+	fmt.Fprintf(os.Stderr, "  %s list-all [FLAGS] \n", app)
+	fmt.Fprint(os.Stderr, "\n")
+	fmt.Fprintln(os.Stderr, "list \"everything\"")
+	fmt.Fprint(os.Stderr, "\n")
+	fmt.Fprint(os.Stderr, "Options:\n")
+	listAllFlags.printDefaults()
 }
 
-func TestReplaceApache20SLHPlaceholders(t *testing.T) {
-	// only test the first line
-	tests := []struct {
-		year     string
-		owner    string
-		expected string
-	}{
-		{"", "", "Copyright [yyyy] [name of copyright owner]"},
-		{"", "Zaphod Beeblebrox", "Copyright [yyyy] Zaphod Beeblebrox"},
-		{"1942", "", "Copyright 1942 [name of copyright owner]"},
-		{"1942", "Zaphod Beeblebrox", "Copyright 1942 Zaphod Beeblebrox"},
-	}
-	b, err := ioutil.ReadFile(filepath.Join("license", strings.ToLower(Apache20.ID())+".slh"))
+// listAllMain is the list-all command. The args are the command's arguments;
+// they don't include the command's name. The returned int is the app's exit
+// code.
+func listAllMain(args []string) int {
+	listAllFlags.Parse(args) // the command's flags exit on error
+	err := listAllFlags.applyEnv()
 	if err != nil {
-		t.Errorf("unexpected error: %q", err)
-		return
+		fmt.Fprintf(os.Stderr, "%s: list-all: %s\n", app, err)
+		return 1
 	}
 
-	a := app
-	for i, test := range tests {
-		a.Owner = test.owner
-		a.Year = test.year
-		v := a.replaceApache20SLHPlaceholders(b)
-		ndx := bytes.IndexByte(v, '\n')
-		if ndx < 0 {
-			t.Errorf("%d: expected to find a \n; none found", i)
-			continue
-		}
+	fmt.Printf("%s: list-all: hello, world\n", app)
 
-		line := string(v[:ndx])
-		if line != test.expected {
-			t.Errorf("%d: got %q want %q", i, line, test.expected)
+	return 0
+}
+`
+	var err error
+	lapp := app
+	lapp.Path, err = ioutil.TempDir("", "quine")
+	if err != nil {
+		panic(err)
+	}
+	c := Command{Name: "list-all", Usage: `list "everything"`}
+	err = lapp.WriteCommandFile(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	file := filepath.Join(lapp.Path, c.File())
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("unexpected error reading %s: %q", file, err)
+	}
+	gots := strings.Split(string(b), "\n")
+	wants := strings.Split(expected, "\n")
+	if len(gots) != len(wants) {
+		t.Fatalf("got %d lines want %d\ngot %q\nwant %q", len(gots), len(wants), string(b), expected)
+	}
+	for i, got := range gots {
+		if got != wants[i] {
+			t.Errorf("%d: got %q\nwant %q", i, got, wants[i])
 		}
+	}
 
+	// an existing command file is not overwritten
+	err = ioutil.WriteFile(file, []byte("package main\n"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = lapp.WriteCommandFile(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	b, err = ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("unexpected error reading %s: %q", file, err)
+	}
+	if string(b) != "package main\n" {
+		t.Errorf("got %q want %q", string(b), "package main\n")
 	}
 }
 
-func TestReplaceGPL20SLHPlaceholders(t *testing.T) {
-	// only test the first line
-	tests := []struct {
-		year     string
-		owner    string
-		expected string
-	}{
-		{"", "", "Copyright (C) yyyy name of author"},
-		{"", "Zaphod Beeblebrox", "Copyright (C) yyyy Zaphod Beeblebrox"},
-		{"1942", "", "Copyright (C) 1942 name of author"},
-		{"1942", "Zaphod Beeblebrox", "Copyright (C) 1942 Zaphod Beeblebrox"},
+func TestWriteMainNotice(t *testing.T) {
+	var err error
+	lapp := app
+	lapp.Path, err = ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
 	}
-	b, err := ioutil.ReadFile(filepath.Join("license", strings.ToLower(GPL20.ID())+".slh"))
+	defer os.RemoveAll(lapp.Path)
+	lapp.Owner = "Trillian"
+	lapp.Year = "1999"
+	lapp.License = GPL30
+	err = lapp.WriteMain()
 	if err != nil {
-		t.Errorf("unexpected error: %q", err)
-		return
+		t.Fatalf("unexpected error: %s", err)
 	}
-
-	a := app
-	for i, test := range tests {
-		a.Owner = test.owner
-		a.Year = test.year
-		v := a.replaceGPL20SLHPlaceholders(b)
-		ndx := bytes.IndexByte(v, '\n')
-		if ndx < 0 {
-			t.Errorf("%d: expected to find a \n; none found", i)
-			continue
+	b, err := ioutil.ReadFile(filepath.Join(lapp.Path, mainFile))
+	if err != nil {
+		t.Fatalf("unexpected error reading %s: %q", filepath.Join(lapp.Path, mainFile), err)
+	}
+	for _, want := range []string{
+		"\tShowW   bool     // show the warranty details\n",
+		"\tflag.BoolVar(&cfg.ShowC, \"show-c\", false, \"show the conditions for conveying the program\")\n",
+		"\tif showLicense() {\n\t\tos.Exit(0)\n\t}\n\tfmt.Fprint(os.Stderr, licenseNotice)\n\n\tos.Exit(testMain())\n",
+		"\t\t{\"show-w\"},\n",
+		"const licenseNotice = \"test  Copyright (C) 1999  Trillian\\n\\n   This program comes with ABSOLUTELY NO WARRANTY; for details run `test -show-w'.\\n",
+		"// licenseShowW is the section of the license that -show-w prints.\nconst licenseShowW = \"15. Disclaimer of Warranty.\\n",
+		"\tif cfg.ShowW {\n\t\tfmt.Print(licenseShowW)\n",
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("%q not found in\n%s", want, b)
 		}
+	}
 
-		line := string(v[:ndx])
-		if line != test.expected {
-			t.Errorf("%d: got %q want %q", i, line, test.expected)
+	for _, notWant := range []string{"embed", "END OF TERMS AND CONDITIONS\\n", "How to Apply These Terms"} {
+		if strings.Contains(string(b), notWant) {
+			t.Errorf("%q found in\n%s", notWant, b)
 		}
+	}
 
+	// the notice's flags can't be defined by the project
+	lapp.Flags = []Flag{{Name: "verbose", Aliases: []string{"show-c"}}}
+	err = lapp.WriteMain()
+	if err == nil || !strings.Contains(err.Error(), "CLI notice: flag show-c: the flag is defined by the project") {
+		t.Errorf("got %v want a flag is defined by the project error", err)
 	}
 }
 
-func TestReplaceGPL30SLHPlaceholders(t *testing.T) {
-	// only test the first line
+func TestSLHVersions(t *testing.T) {
 	tests := []struct {
-		year     string
-		owner    string
+		license  License
 		expected string
 	}{
-		{"", "", "Copyright (C) <year> <name of author>"},
-		{"", "Zaphod Beeblebrox", "Copyright (C) <year> Zaphod Beeblebrox"},
-		{"1942", "", "Copyright (C) 1942 <name of author>"},
-		{"1942", "Zaphod Beeblebrox", "Copyright (C) 1942 Zaphod Beeblebrox"},
-	}
-	b, err := ioutil.ReadFile(filepath.Join("license", strings.ToLower(GPL30.ID())+".slh"))
-	if err != nil {
-		t.Errorf("unexpected error: %q", err)
-		return
-	}
-
-	a := app
-	for i, test := range tests {
-		a.Owner = test.owner
-		a.Year = test.year
-		v := a.replaceGPL30SLHPlaceholders(b)
-		ndx := bytes.IndexByte(v, '\n')
-		if ndx < 0 {
-			t.Errorf("%d: expected to find a \n; none found", i)
+		{GPL20, "Free Software Foundation; version 2."},
+		{GPL20Only, "Free Software Foundation; version 2."},
+		{GPL20OrLater, "Free Software Foundation; either version 2 of the License, or (at your option) any later version."},
+		{GPL30Only, "Free Software Foundation, version 3."},
+		{GPL30OrLater, "Free Software Foundation, either version 3 of the License, or (at your option) any later version."},
+		{LGPL20OrLater, "Free Software Foundation; either version 2 of the License, or (at your option) any later version."},
+		{LGPL21Only, "Free Software Foundation; version 2.1."},
+		{LGPL21OrLater, "Free Software Foundation; either version 2.1 of the License, or (at your option) any later version."},
+		{LGPL30OrLater, "Free Software Foundation, either version 3 of the License, or (at your option) any later version."},
+	}
+	for _, test := range tests {
+		b, _, err := readLicenseFile(licenseFiles(test.license)[1])
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.license, err)
 			continue
 		}
-
-		line := string(v[:ndx])
-		if line != test.expected {
-			t.Errorf("%d: got %q want %q", i, line, test.expected)
+		if !strings.Contains(string(b), test.expected) {
+			t.Errorf("%s: %q not found in\n%s", test.license, test.expected, b)
 		}
-
 	}
 }
 
-func TestReplaceLGPL2SLHPlaceholders(t *testing.T) {
-	// only test the first line
+func TestCheckLicenseText(t *testing.T) {
 	tests := []struct {
-		year     string
-		owner    string
-		expected string
+		license string
+		err     string
 	}{
-		{"", "", "Copyright (C) year name of author"},
-		{"", "Zaphod Beeblebrox", "Copyright (C) year Zaphod Beeblebrox"},
-		{"1942", "", "Copyright (C) 1942 name of author"},
-		{"1942", "Zaphod Beeblebrox", "Copyright (C) 1942 Zaphod Beeblebrox"},
-	}
-	// LGPL-2.0
-	b, err := ioutil.ReadFile(filepath.Join("license", strings.ToLower(LGPL20.ID())+".slh"))
-	if err != nil {
-		t.Errorf("unexpected error: %q", err)
-		return
-	}
-
-	a := app
-	for i, test := range tests {
-		a.Owner = test.owner
-		a.Year = test.year
-		v := a.replaceLGPL2SLHPlaceholders(b)
-		ndx := bytes.IndexByte(v, '\n')
-		if ndx < 0 {
-			t.Errorf("%d: expected to find a \n; none found", i)
+		{"", ""},
+		{"MIT", ""},
+		{"EPL-2.0", ""},
+		{"AGPL-3.0-only", ""},
+		{"AGPL-3.0-or-later", ""},
+		{"WTFPL", "WTFPL: the license's text isn't in the license corpus"},
+		{"Apache-1.1", "Apache-1.1: the license's text isn't in the license corpus"},
+		{"MIT OR EPL-2.0", ""},
+		{"MIT OR WTFPL", "WTFPL: the license's text isn't in the license corpus"},
+		{"GPL-2.0+ WITH Classpath-exception-2.0", ""},
+	}
+	for _, test := range tests {
+		lapp := app
+		lapp.License, lapp.Expression = "", nil
+		expr, err := ParseLicenseExpr(test.license)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.license, err)
 			continue
 		}
-
-		line := string(v[:ndx])
-		if line != test.expected {
-			t.Errorf("%d: got %q want %q", i, line, test.expected)
+		switch {
+		case expr == nil:
+		case expr.IsLicense():
+			lapp.License = expr.License
+		default:
+			lapp.Expression = expr
 		}
-
-	}
-
-	// LGPL-2.1
-	b, err = ioutil.ReadFile(filepath.Join("license", strings.ToLower(LGPL21.ID())+".slh"))
-	if err != nil {
-		t.Errorf("unexpected error: %q", err)
-		return
-	}
-
-	for i, test := range tests {
-		a.Owner = test.owner
-		a.Year = test.year
-		v := a.replaceLGPL2SLHPlaceholders(b)
-		ndx := bytes.IndexByte(v, '\n')
-		if ndx < 0 {
-			t.Errorf("%d: expected to find a \n; none found", i)
+		err = lapp.checkLicenseText()
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", test.license, err)
+			}
 			continue
 		}
-
-		line := string(v[:ndx])
-		if line != test.expected {
-			t.Errorf("%d: got %q want %q", i, line, test.expected)
+		if err == nil || !strings.HasPrefix(err.Error(), test.err) {
+			t.Errorf("%s: got %v want %q", test.license, err, test.err)
 		}
-
 	}
 }
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// spdxFile is the license file that has the SPDX License List. It can be
+// replaced by a newer licenses.json, or any other list in its format, in the
+// license override directory.
+const spdxFile = "spdx.json"
+
+// spdxExceptionsFile is the license file that has the SPDX License List's
+// exceptions; like spdxFile, it can be replaced by the list's
+// exceptions.json.
+const spdxExceptionsFile = "spdx-exceptions.json"
+
+// spdxLicense is a license in the SPDX License List. The field names are
+// those of the list's licenses.json.
+type spdxLicense struct {
+	ID         string `json:"licenseId"`
+	Name       string `json:"name"`
+	OSI        bool   `json:"isOsiApproved"`
+	FSF        bool   `json:"isFsfLibre"`
+	Deprecated bool   `json:"isDeprecatedLicenseId"`
+	Header     string `json:"standardLicenseHeader"` // the SLH; empty if the license doesn't have one
+}
+
+// spdxException is an exception in the SPDX License List: an additional
+// permission that is added to a license with WITH. The field names are those
+// of the list's exceptions.json.
+type spdxException struct {
+	ID         string `json:"licenseExceptionId"`
+	Name       string `json:"name"`
+	Deprecated bool   `json:"isDeprecatedLicenseId"`
+}
+
+// spdxIndex is the SPDX License List, indexed for LicenseFromString.
+type spdxIndex struct {
+	licenses   []spdxLicense            // in the list's order
+	byID       map[string]spdxLicense   // by SPDX Identifier
+	keys       map[string]string        // the SPDX Identifier of each match key
+	exceptions map[string]spdxException // by the match key of their ID and name
+}
+
+// licenseAliases are the names that quine accepts for a license in addition
+// to those in the SPDX License List.
+var licenseAliases = map[string]License{
+	"BSD-2": BSD2Clause,
+	"BSD-3": BSD3Clause,
+	// SPDX calls it the Library GPL
+	"GNU Lesser General Public License v2.0 only":     LGPL20Only,
+	"GNU Lesser General Public License v2.0 or later": LGPL20OrLater,
+}
+
+// the index of the SPDX License List and the license override directory it
+// was loaded from; the directory can change.
+var (
+	spdxIdx *spdxIndex
+	spdxDir string
+)
+
+// spdxLicenses returns the index of the SPDX License List. The list is read
+// the first time it is needed, and again if the license override directory
+// changes.
+func spdxLicenses() (*spdxIndex, error) {
+	dir := licenseOverrideDir()
+	if spdxIdx != nil && spdxDir == dir {
+		return spdxIdx, nil
+	}
+	b, _, err := readLicenseFile(spdxFile)
+	if err != nil {
+		return nil, fmt.Errorf("SPDX license list: %s", err)
+	}
+	var list struct {
+		Licenses []spdxLicense `json:"licenses"`
+	}
+	err = json.Unmarshal(b, &list)
+	if err != nil {
+		return nil, fmt.Errorf("SPDX license list: %s: %s", spdxFile, err)
+	}
+	b, _, err = readLicenseFile(spdxExceptionsFile)
+	if err != nil {
+		return nil, fmt.Errorf("SPDX license list: %s", err)
+	}
+	var exceptions struct {
+		Exceptions []spdxException `json:"exceptions"`
+	}
+	err = json.Unmarshal(b, &exceptions)
+	if err != nil {
+		return nil, fmt.Errorf("SPDX license list: %s: %s", spdxExceptionsFile, err)
+	}
+	idx := newSPDXIndex(list.Licenses)
+	for _, e := range exceptions.Exceptions {
+		for _, s := range []string{e.ID, e.Name} {
+			if _, ok := idx.exceptions[matchKey(s)]; !ok {
+				idx.exceptions[matchKey(s)] = e
+			}
+		}
+	}
+	spdxIdx, spdxDir = idx, dir
+	return spdxIdx, nil
+}
+
+// newSPDXIndex returns the index of the licenses. If more than one license
+// has the same match key, the first one wins: the identifiers come first,
+// followed by the aliases, then the names, so that a name can't shadow an
+// identifier. The names of deprecated licenses come last, so that a name,
+// e.g. GNU General Public License v2.0 only, is the current license,
+// GPL-2.0-only, not the deprecated one, GPL-2.0.
+func newSPDXIndex(licenses []spdxLicense) *spdxIndex {
+	idx := &spdxIndex{
+		licenses:   licenses,
+		byID:       make(map[string]spdxLicense, len(licenses)),
+		keys:       make(map[string]string),
+		exceptions: make(map[string]spdxException),
+	}
+	add := func(s, id string) {
+		k := matchKey(s)
+		if _, ok := idx.keys[k]; !ok {
+			idx.keys[k] = id
+		}
+	}
+	for _, l := range licenses {
+		idx.byID[l.ID] = l
+		add(l.ID, l.ID)
+		add(dropZero(l.ID), l.ID)
+	}
+	for s, l := range licenseAliases {
+		for _, v := range nameVariants(s) {
+			add(v, string(l))
+		}
+	}
+	for _, deprecated := range []bool{false, true} {
+		for _, l := range licenses {
+			if l.Deprecated != deprecated {
+				continue
+			}
+			for _, v := range nameVariants(l.Name) {
+				add(v, l.ID)
+			}
+		}
+	}
+	return idx
+}
+
+// Lookup returns the SPDX License List's entry for the license.
+func (idx *spdxIndex) Lookup(l License) (spdxLicense, bool) {
+	sl, ok := idx.byID[string(l)]
+	return sl, ok
+}
+
+// LookupException returns the SPDX License List's exception whose ID or
+// name is s; it is matched the same way that licenses are.
+func (idx *spdxIndex) LookupException(s string) (spdxException, bool) {
+	e, ok := idx.exceptions[matchKey(s)]
+	return e, ok
+}
+
+// matchKey returns the key that s is matched on: s upper-cased with only its
+// letters, digits, and +'s, which is significant in an SPDX Identifier.
+func matchKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, s)
+}
+
+// zeroRe matches a version's trailing .0, e.g. the .0 of 2.0 but not of 2.01.
+var zeroRe = regexp.MustCompile(`\.0\b`)
+
+// dropZero returns s without the trailing .0 of its versions.
+func dropZero(s string) string {
+	return zeroRe.ReplaceAllString(s, "")
+}
+
+// quotedRe matches the quoted words of a name, e.g. "New" or "Revised".
+var quotedRe = regexp.MustCompile(`\s*"[^"]*"(\s+or\s+"[^"]*")*`)
+
+// nameVariants returns the name, with its quotes removed, along with its
+// variants: without its quoted words, without a leading GNU, without a
+// trailing only, without the trailing .0 of its versions, and any
+// combination of them.
+func nameVariants(name string) []string {
+	vs := []string{strings.Replace(name, `"`, "", -1)}
+	if s := quotedRe.ReplaceAllString(name, ""); s != name {
+		vs = append(vs, s)
+	}
+	variants := []func(string) string{
+		func(s string) string { return strings.TrimPrefix(s, "GNU ") },
+		func(s string) string { return strings.TrimSuffix(s, " only") },
+		dropZero,
+	}
+	for _, f := range variants {
+		for _, s := range vs {
+			if v := f(s); v != s {
+				vs = append(vs, v)
+			}
+		}
+	}
+	return vs
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNameVariants(t *testing.T) {
+	got := nameVariants(`BSD 3-Clause "New" or "Revised" License`)
+	for _, want := range []string{"BSD 3-Clause New or Revised License", "BSD 3-Clause License"} {
+		var found bool
+		for _, v := range got {
+			found = found || v == want
+		}
+		if !found {
+			t.Errorf("%q not in %q", want, got)
+		}
+	}
+	got = nameVariants("GNU General Public License v2.0 only")
+	if len(got) != 8 {
+		t.Errorf("got %d variants want 8: %q", len(got), got)
+	}
+}
+
+func TestSPDXLicense(t *testing.T) {
+	lapp := app
+	lapp.Owner = "Trillian"
+	lapp.Year = "1999"
+
+	// any license in the list is accepted, even if its text isn't in the corpus
+	for _, s := range []string{"WTFPL", "Apache-1.1", "Apache License 1.1", "EPL-2.0", "AGPL-3.0"} {
+		_, err := LicenseFromString(s)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", s, err)
+		}
+	}
+
+	// the AGPL's text and SLH are in the corpus
+	lapp.License = AGPL30OrLater
+	s, err := lapp.slh()
+	if err != nil {
+		t.Fatalf("slh: unexpected error: %s", err)
+	}
+	if !strings.HasPrefix(s, "// Copyright (C) 1999 Trillian\n// This program is free software") || !strings.Contains(s, "either version 3 of the License") {
+		t.Errorf("slh: got %q", s)
+	}
+	op, err := lapp.licenseOp()
+	if err != nil {
+		t.Fatalf("license: unexpected error: %s", err)
+	}
+	if !strings.HasPrefix(string(op.Data), "GNU AFFERO GENERAL PUBLIC LICENSE") {
+		t.Errorf("license: got %.40q", op.Data)
+	}
+
+	// the WTFPL's text isn't in the corpus
+	lapp.License = License("WTFPL")
+	_, err = lapp.licenseOp()
+	if err == nil || !strings.Contains(err.Error(), "add it, as wtfpl, to the license override directory") {
+		t.Errorf("license: got %v want a text isn't in the license corpus error", err)
+	}
+
+	// without an SLH file, the SLH comes from the SPDX license list
+	dir, err := ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	err = ioutil.WriteFile(filepath.Join(dir, spdxFile), []byte(`{"licenses": [{"licenseId": "WTFPL", "name": "Do What The F*ck You Want To Public License", "standardLicenseHeader": "Copyright <year> <owner>\nDo what you want."}]}`), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ld := licenseDir
+	defer func() { licenseDir = ld }()
+	licenseDir = dir
+	s, err = lapp.slh()
+	if err != nil {
+		t.Fatalf("slh: unexpected error: %s", err)
+	}
+	if s != "// Copyright 1999 Trillian\n// Do what you want.\n\n" {
+		t.Errorf("slh: got %q", s)
+	}
+}
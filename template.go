@@ -0,0 +1,162 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/template"
+)
+
+// The templates of the generated files:
+//
+//	main.go.tmpl      main.go; it is regenerated every time.
+//	app_main.go.tmpl  <app>_main.go; it is only written if it doesn't exist.
+//	cmd.go.tmpl       <cmd>_cmd.go; it is only written if it doesn't exist.
+//
+// Any of them can be replaced by a file with the same name in the template
+// directory; see templateOverrideDir.
+const (
+	mainTmpl    = "main.go.tmpl"
+	appMainTmpl = "app_main.go.tmpl"
+	cmdTmpl     = "cmd.go.tmpl"
+)
+
+// overridesDir is the directory, in the QUINEPATH, of the template overrides.
+const overridesDir = "overrides"
+
+// templateNames are the names of the templates that can be overridden.
+var templateNames = []string{mainTmpl, appMainTmpl, cmdTmpl}
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// flagSetData is the data of the flagSet template.
+type flagSetData struct {
+	Name   string // the name of the flagSet var
+	Desc   string // what the flags are; used in the var's comment
+	Set    string // the expression of the flag.FlagSet
+	Prefix string // the prefix of the flags' environment variables
+	Flags  []Flag
+}
+
+// templateOverrideDir returns the directory that the template overrides are
+// in: the -templatedir flag's value, if set, otherwise the overrides directory
+// in the QUINEPATH. If neither is set, the built-in templates are used.
+func templateOverrideDir() string {
+	if templateDir != "" {
+		return templateDir
+	}
+	if quinePath != "" {
+		return filepath.Join(quinePath, overridesDir)
+	}
+	return ""
+}
+
+// templates returns the templates of the generated files along with the
+// files of any templates that were overridden, by template name. The funcs
+// that the templates use are bound to the App.
+//
+// A template that is overridden replaces the built-in template of the same
+// name, along with any templates that it defines. The templates that the
+// built-in template defines, e.g. usage, that the override doesn't define
+// are still available to it.
+func (a *App) templates() (*template.Template, map[string]string, error) {
+	funcs := template.FuncMap{
+		"appFlags": func() ([]Flag, error) {
+			flags, err := a.flags()
+			if err != nil {
+				return nil, err
+			}
+			return append([]Flag{{Name: "logfile"}}, flags...), nil
+		},
+		"comment":   a.wrapper.Line,
+		"envPrefix": envPrefix,
+		"flags":     a.flags,
+		"header":    a.header,
+		"flagSetData": func(name, desc, set, prefix string, flags []Flag) flagSetData {
+			return flagSetData{Name: name, Desc: desc, Set: set, Prefix: prefix, Flags: flags}
+		},
+		"notice": a.notice,
+		"quote":  strconv.Quote,
+		"register": func(set, cfg string, f Flag) (string, error) {
+			return f.Register(set, cfg)
+		},
+		"slh":          a.slh,
+		"usesDuration": a.usesDuration,
+	}
+	t, err := template.New("quine").Funcs(funcs).ParseFS(templateFS, "templates/*.tmpl")
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse templates: %s", err)
+	}
+
+	overrides := map[string]string{}
+	dir := templateOverrideDir()
+	if dir == "" {
+		return t, overrides, nil
+	}
+	for _, name := range templateNames {
+		file := filepath.Join(dir, name)
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			if os.IsNotExist(err) { // not overridden; use the built-in template
+				continue
+			}
+			return nil, nil, fmt.Errorf("template override: %s", err)
+		}
+		_, err = t.New(name).Parse(string(b))
+		if err != nil {
+			return nil, nil, fmt.Errorf("template override: parse %s: %s", file, err)
+		}
+		overrides[name] = file
+	}
+	return t, overrides, nil
+}
+
+// render executes the template, name, with data and formats the result. If
+// the template was overridden, the output must still be valid Go source.
+func (a *App) render(name string, data interface{}) ([]byte, error) {
+	t, overrides, err := a.templates()
+	if err != nil {
+		return nil, err
+	}
+	a.buf.Reset()
+	err = t.ExecuteTemplate(&a.buf, name, data)
+	if err != nil {
+		if file, ok := overrides[name]; ok {
+			return nil, fmt.Errorf("template override %s: %s", file, err)
+		}
+		return nil, fmt.Errorf("execute template: %s", err)
+	}
+
+	// fmt the code
+	fmtd, err := format.Source(a.buf.Bytes())
+	if err != nil {
+		if file, ok := overrides[name]; ok {
+			return nil, fmt.Errorf("template override %s: output is not valid Go source: %s", file, err)
+		}
+		return nil, fmt.Errorf("fmt source: %s", err)
+	}
+	return fmtd, nil
+}
+
+// usesDuration returns whether any of the app's flags, including those of
+// its commands, is a duration; if so main.go needs to import time.
+func (a *App) usesDuration() bool {
+	for _, f := range a.Flags {
+		if f.kind() == "duration" {
+			return true
+		}
+	}
+	for _, c := range a.Commands {
+		for _, f := range c.Flags {
+			if f.kind() == "duration" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTemplateOverride(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func() { templateDir = "" }()
+	templateDir = dir
+
+	// only main.go is overridden; it can use the templates defined by the
+	// built-in template.
+	override := `package main
+
+import "flag"
+
+var cfg Config
+
+type Config struct {
+{{- template "fields" .Flags}}
+}
+
+func main() {
+	flag.Parse()
+	os.Exit({{.Name}}Main())
+}
+`
+	expected := `package main
+
+import "flag"
+
+var cfg Config
+
+type Config struct {
+	Addr string // listen address
+}
+
+func main() {
+	flag.Parse()
+	os.Exit(testMain())
+}
+`
+	err = ioutil.WriteFile(filepath.Join(dir, mainTmpl), []byte(override), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := app
+	a.License = None
+	a.Flags = []Flag{{Name: "addr", Usage: "listen address"}}
+	b, err := a.render(mainTmpl, &a)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(b) != expected {
+		t.Errorf("got %q\nwant %q", string(b), expected)
+	}
+
+	// the app file isn't overridden
+	b, err = a.render(appMainTmpl, &a)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.Contains(string(b), "func testMain() int {") {
+		t.Errorf("expected the built-in app file template to be used; got %q", string(b))
+	}
+
+	// an override whose output isn't valid Go is an error
+	err = ioutil.WriteFile(filepath.Join(dir, mainTmpl), []byte("package main\n\nfunc {{.Name}}("), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = a.render(mainTmpl, &a)
+	if err == nil {
+		t.Fatal("expected an error, got none")
+	}
+	want := "template override " + filepath.Join(dir, mainTmpl) + ": output is not valid Go source"
+	if !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("got %q want prefix %q", err, want)
+	}
+
+	// as is an override that can't be parsed
+	err = ioutil.WriteFile(filepath.Join(dir, mainTmpl), []byte("package main\n{{if}}"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = a.render(mainTmpl, &a)
+	if err == nil {
+		t.Fatal("expected an error, got none")
+	}
+	want = "template override: parse " + filepath.Join(dir, mainTmpl)
+	if !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("got %q want prefix %q", err, want)
+	}
+}
+
+func TestTemplateOverrideDir(t *testing.T) {
+	qp := quinePath
+	defer func() {
+		quinePath = qp
+		templateDir = ""
+	}()
+	tests := []struct {
+		quinePath   string
+		templateDir string
+		expected    string
+	}{
+		{"", "", ""},
+		{"/quine", "", filepath.Join("/quine", overridesDir)},
+		{"", "tmpl", "tmpl"},
+		{"/quine", "tmpl", "tmpl"},
+	}
+	for i, test := range tests {
+		quinePath = test.quinePath
+		templateDir = test.templateDir
+		got := templateOverrideDir()
+		if got != test.expected {
+			t.Errorf("%d: got %q want %q", i, got, test.expected)
+		}
+	}
+}